@@ -0,0 +1,130 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package xmppbind provides resource binding request and response helpers, as defined
+// in RFC 6120 section 7 and XEP-0386 (Bind 2).
+package xmppbind
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackal-xmpp/stravaganza"
+	stanzaerror "github.com/jackal-xmpp/stravaganza/errors/stanza"
+	"github.com/jackal-xmpp/stravaganza/jid"
+)
+
+// Namespace is the RFC 6120 resource binding namespace.
+const Namespace = "urn:ietf:params:xml:ns:xmpp-bind"
+
+// NewRequest returns a resource binding IQ request.
+// resource is optional, and when empty the server will be asked to generate one on behalf of the client.
+func NewRequest(id string, from, to *jid.JID, resource string) (*stravaganza.IQ, error) {
+	b := stravaganza.NewBuilder("bind").
+		WithAttribute(stravaganza.Namespace, Namespace)
+	if len(resource) > 0 {
+		b.WithChild(stravaganza.NewBuilder("resource").WithText(resource).Build())
+	}
+	return stravaganza.NewIQBuilder().
+		WithAttribute(stravaganza.ID, id).
+		WithAttribute(stravaganza.Type, stravaganza.SetType).
+		WithAttribute(stravaganza.From, from.String()).
+		WithAttribute(stravaganza.To, to.String()).
+		WithChild(b.Build()).
+		BuildIQ()
+}
+
+// ParseRequest parses a resource binding IQ request, returning the requested resource,
+// which will be empty if the client asked the server to generate one.
+// Returned resource is already prepared according to Resourceprep.
+// Any returned error will be a *stanzaerror.Error value, ready to be sent back to the client.
+func ParseRequest(iq *stravaganza.IQ) (string, error) {
+	bind := iq.ChildNamespace("bind", Namespace)
+	if !iq.IsSet() || bind == nil || iq.ChildrenCount() != 1 {
+		return "", stanzaerror.E(stanzaerror.BadRequest, iq)
+	}
+	res := bind.Child("resource")
+	if res == nil || len(res.Text()) == 0 {
+		return "", nil
+	}
+	fromJID := iq.FromJID()
+	j, err := jid.New(fromJID.Node(), fromJID.Domain(), res.Text(), false)
+	if err != nil {
+		return "", &stanzaerror.Error{
+			Reason:      stanzaerror.BadRequest,
+			SentElement: iq,
+			Text:        fmt.Sprintf("invalid resource: %v", err),
+		}
+	}
+	return j.Resource(), nil
+}
+
+// NewResult returns the result to a resource binding IQ request, informing the client about the bound full JID.
+func NewResult(iq *stravaganza.IQ, boundJID *jid.JID) (*stravaganza.IQ, error) {
+	if !boundJID.IsFullWithUser() {
+		return nil, fmt.Errorf("xmppbind: not a full JID: %s", boundJID.String())
+	}
+	return iq.ResultBuilder().
+		WithChild(
+			stravaganza.NewBuilder("bind").
+				WithAttribute(stravaganza.Namespace, Namespace).
+				WithChild(
+					stravaganza.NewBuilder("jid").
+						WithText(boundJID.String()).
+						Build(),
+				).
+				Build(),
+		).
+		BuildIQ()
+}
+
+// ParseResult parses a resource binding IQ response, returning the bound full JID.
+// In case the response is an error, the returned error will be a *stanzaerror.Error value.
+func ParseResult(iq *stravaganza.IQ) (*jid.JID, error) {
+	if iq.IsError() {
+		se, err := stanzaerror.FromStanza(iq)
+		if err != nil {
+			return nil, err
+		}
+		return nil, se
+	}
+	bind := iq.ChildNamespace("bind", Namespace)
+	if !iq.IsResult() || bind == nil {
+		return nil, errors.New("xmppbind: not a resource binding result")
+	}
+	jidEl := bind.Child("jid")
+	if jidEl == nil {
+		return nil, errors.New("xmppbind: missing bound jid")
+	}
+	j, err := jid.NewWithString(jidEl.Text(), false)
+	if err != nil {
+		return nil, err
+	}
+	if !j.IsFullWithUser() {
+		return nil, fmt.Errorf("xmppbind: not a full JID: %s", j.String())
+	}
+	return j, nil
+}
+
+// ConflictError returns the error to be sent back when the requested resource is already
+// in use and the server policy doesn't allow to terminate the existing session.
+func ConflictError(iq *stravaganza.IQ) *stanzaerror.Error {
+	return stanzaerror.E(stanzaerror.Conflict, iq)
+}
+
+// NotAllowedError returns the error to be sent back when the client is not allowed to bind
+// a resource, such as when it reached the maximum number of bound resources.
+func NotAllowedError(iq *stravaganza.IQ) *stanzaerror.Error {
+	return stanzaerror.E(stanzaerror.NotAllowed, iq)
+}
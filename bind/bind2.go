@@ -0,0 +1,161 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppbind
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackal-xmpp/stravaganza"
+)
+
+// Bind2Namespace is the XEP-0386 (Bind 2) namespace.
+const Bind2Namespace = "urn:xmpp:bind:0"
+
+// Bind2Feature represents the Bind 2 feature, advertised inline within SASL2 <authentication/> feature.
+type Bind2Feature struct {
+	// InlineFeatures contains the namespaces of the features that can be negotiated along with binding.
+	InlineFeatures []string
+}
+
+// Element returns the <bind/> feature element representation of f.
+func (f *Bind2Feature) Element() stravaganza.Element {
+	b := stravaganza.NewBuilder("bind").
+		WithAttribute(stravaganza.Namespace, Bind2Namespace)
+	if len(f.InlineFeatures) > 0 {
+		ib := stravaganza.NewBuilder("inline")
+		for _, ns := range f.InlineFeatures {
+			ib.WithChild(stravaganza.NewBuilder("feature").WithAttribute("var", ns).Build())
+		}
+		b.WithChild(ib.Build())
+	}
+	return b.Build()
+}
+
+// Supports tells whether namespace feature can be negotiated along with binding.
+func (f *Bind2Feature) Supports(namespace string) bool {
+	for _, ns := range f.InlineFeatures {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseBind2Feature parses a Bind 2 <bind/> feature element.
+func ParseBind2Feature(el stravaganza.Element) (*Bind2Feature, error) {
+	if !isBind2Element(el, "bind") {
+		return nil, errors.New("xmppbind: not a bind2 feature element")
+	}
+	f := &Bind2Feature{}
+	if inline := el.Child("inline"); inline != nil {
+		for _, feature := range inline.Children("feature") {
+			if ns := feature.Attribute("var"); len(ns) > 0 {
+				f.InlineFeatures = append(f.InlineFeatures, ns)
+			}
+		}
+	}
+	return f, nil
+}
+
+// Bind2Request represents a Bind 2 request, sent within SASL2 <authenticate/> element.
+type Bind2Request struct {
+	// Tag is an optional client identifier, used by the server as part of the generated resource.
+	Tag string
+
+	// Payloads contains the inline feature requests (e.g. stream management or carbons enabling).
+	Payloads []stravaganza.Element
+}
+
+// Element returns the <bind/> request element representation of r.
+func (r *Bind2Request) Element() stravaganza.Element {
+	b := stravaganza.NewBuilder("bind").
+		WithAttribute(stravaganza.Namespace, Bind2Namespace)
+	if len(r.Tag) > 0 {
+		b.WithChild(stravaganza.NewBuilder("tag").WithText(r.Tag).Build())
+	}
+	return b.WithChildren(r.Payloads...).Build()
+}
+
+// Payload returns the inline feature request payload associated to namespace, or nil if not present.
+func (r *Bind2Request) Payload(namespace string) stravaganza.Element {
+	for _, p := range r.Payloads {
+		if stravaganza.NamespaceURI(p) == namespace {
+			return p
+		}
+	}
+	return nil
+}
+
+// Resource returns the resource to be bound by the server, composed from the request tag and
+// a server generated unique suffix. In absence of tag the suffix is returned.
+func (r *Bind2Request) Resource(suffix string) string {
+	tag := strings.TrimSpace(r.Tag)
+	if len(tag) == 0 {
+		return suffix
+	}
+	return tag + "." + suffix
+}
+
+// ParseBind2Request parses a Bind 2 <bind/> request element.
+func ParseBind2Request(el stravaganza.Element) (*Bind2Request, error) {
+	if !isBind2Element(el, "bind") {
+		return nil, errors.New("xmppbind: not a bind2 request element")
+	}
+	r := &Bind2Request{}
+	for _, child := range el.AllChildren() {
+		if isBind2Tag(child) {
+			r.Tag = child.Text()
+			continue
+		}
+		r.Payloads = append(r.Payloads, child)
+	}
+	return r, nil
+}
+
+// Bound represents a Bind 2 <bound/> response, sent within SASL2 <success/> element.
+type Bound struct {
+	// Payloads contains the inline feature responses.
+	Payloads []stravaganza.Element
+}
+
+// Element returns the <bound/> element representation of b.
+func (b *Bound) Element() stravaganza.Element {
+	return stravaganza.NewBuilder("bound").
+		WithAttribute(stravaganza.Namespace, Bind2Namespace).
+		WithChildren(b.Payloads...).
+		Build()
+}
+
+// ParseBound parses a Bind 2 <bound/> response element.
+func ParseBound(el stravaganza.Element) (*Bound, error) {
+	if !isBind2Element(el, "bound") {
+		return nil, errors.New("xmppbind: not a bind2 bound element")
+	}
+	return &Bound{Payloads: el.AllChildren()}, nil
+}
+
+func isBind2Element(el stravaganza.Element, name string) bool {
+	return el != nil && stravaganza.LocalName(el) == name && stravaganza.NamespaceURI(el) == Bind2Namespace
+}
+
+// isBind2Tag tells whether el is a <tag/> element, either in Bind 2 namespace or inheriting it from its parent.
+func isBind2Tag(el stravaganza.Element) bool {
+	if stravaganza.LocalName(el) != "tag" {
+		return false
+	}
+	ns := stravaganza.NamespaceURI(el)
+	return len(ns) == 0 || ns == Bind2Namespace
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppbind
+
+import (
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBind2_Feature(t *testing.T) {
+	// given
+	f := &Bind2Feature{InlineFeatures: []string{"urn:xmpp:carbons:2", "urn:xmpp:csi:0", "urn:xmpp:sm:3"}}
+
+	// when
+	el := f.Element()
+	f2, err := ParseBind2Feature(el)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, `<bind xmlns='urn:xmpp:bind:0'><inline>`+
+		`<feature var='urn:xmpp:carbons:2'/><feature var='urn:xmpp:csi:0'/><feature var='urn:xmpp:sm:3'/>`+
+		`</inline></bind>`, el.String())
+	require.Equal(t, f, f2)
+	require.True(t, f2.Supports("urn:xmpp:sm:3"))
+	require.False(t, f2.Supports("urn:xmpp:mam:2"))
+}
+
+func TestBind2_Request(t *testing.T) {
+	// given
+	r := &Bind2Request{
+		Tag: "AwesomeXMPP",
+		Payloads: []stravaganza.Element{
+			stravaganza.NewBuilder("enable").WithAttribute(stravaganza.Namespace, "urn:xmpp:carbons:2").Build(),
+			stravaganza.NewBuilder("inactive").WithAttribute(stravaganza.Namespace, "urn:xmpp:csi:0").Build(),
+		},
+	}
+
+	// when
+	el := r.Element()
+	r2, err := ParseBind2Request(el)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, `<bind xmlns='urn:xmpp:bind:0'><tag>AwesomeXMPP</tag>`+
+		`<enable xmlns='urn:xmpp:carbons:2'/><inactive xmlns='urn:xmpp:csi:0'/></bind>`, el.String())
+	require.Equal(t, "AwesomeXMPP", r2.Tag)
+	require.Len(t, r2.Payloads, 2)
+	require.Equal(t, "inactive", r2.Payload("urn:xmpp:csi:0").Name())
+	require.Nil(t, r2.Payload("urn:xmpp:sm:3"))
+	require.Equal(t, "AwesomeXMPP.5f2a", r2.Resource("5f2a"))
+	require.Equal(t, "5f2a", (&Bind2Request{}).Resource("5f2a"))
+}
+
+func TestBind2_RequestNamespacedTag(t *testing.T) {
+	// given
+	el := stravaganza.NewBuilder("bind").
+		WithAttribute(stravaganza.Namespace, Bind2Namespace).
+		WithChild(
+			stravaganza.NewBuilder("b:tag").
+				WithAttribute("xmlns:b", Bind2Namespace).
+				WithText("AwesomeXMPP").
+				Build(),
+		).
+		WithChild(
+			stravaganza.NewBuilder("tag").
+				WithAttribute(stravaganza.Namespace, "urn:example:other").
+				Build(),
+		).
+		Build()
+
+	// when
+	r, err := ParseBind2Request(el)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, "AwesomeXMPP", r.Tag)
+	require.Len(t, r.Payloads, 1)
+	require.NotNil(t, r.Payload("urn:example:other"))
+}
+
+func TestBind2_Bound(t *testing.T) {
+	// given
+	b := &Bound{
+		Payloads: []stravaganza.Element{
+			stravaganza.NewBuilder("enabled").WithAttribute(stravaganza.Namespace, "urn:xmpp:sm:3").WithAttribute("id", "abc").Build(),
+		},
+	}
+
+	// when
+	el := b.Element()
+	b2, err := ParseBound(el)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, `<bound xmlns='urn:xmpp:bind:0'><enabled xmlns='urn:xmpp:sm:3' id='abc'/></bound>`, el.String())
+	require.Len(t, b2.Payloads, 1)
+
+	_, err = ParseBound(stravaganza.NewBuilder("bound").Build())
+	require.NotNil(t, err)
+	_, err = ParseBind2Request(el)
+	require.NotNil(t, err)
+}
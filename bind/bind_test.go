@@ -0,0 +1,157 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppbind
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza"
+	stanzaerror "github.com/jackal-xmpp/stravaganza/errors/stanza"
+	"github.com/jackal-xmpp/stravaganza/jid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBind_Request(t *testing.T) {
+	// given
+	from, _ := jid.NewWithString("juliet@im.example.com", false)
+	to, _ := jid.NewWithString("im.example.com", false)
+
+	// when
+	iq, err := NewRequest("yhc13a95", from, to, "balcony")
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, `<iq id='yhc13a95' type='set' from='juliet@im.example.com' to='im.example.com'>`+
+		`<bind xmlns='urn:ietf:params:xml:ns:xmpp-bind'><resource>balcony</resource></bind></iq>`, iq.String())
+
+	res, err := ParseRequest(iq)
+	require.Nil(t, err)
+	require.Equal(t, "balcony", res)
+}
+
+func TestBind_ServerGeneratedResource(t *testing.T) {
+	// given
+	from, _ := jid.NewWithString("juliet@im.example.com", false)
+	to, _ := jid.NewWithString("im.example.com", false)
+	iq, _ := NewRequest("tn281v37", from, to, "")
+
+	// when
+	res, err := ParseRequest(iq)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, "", res)
+	require.Equal(t, `<bind xmlns='urn:ietf:params:xml:ns:xmpp-bind'/>`, iq.AllChildren()[0].String())
+}
+
+func TestBind_ParseRequestErrors(t *testing.T) {
+	var tcs = []struct {
+		name    string
+		iqType  string
+		payload stravaganza.Element
+	}{
+		{
+			name:    "WrongType",
+			iqType:  stravaganza.GetType,
+			payload: stravaganza.NewBuilder("bind").WithAttribute(stravaganza.Namespace, Namespace).Build(),
+		},
+		{
+			name:    "WrongNamespace",
+			iqType:  stravaganza.SetType,
+			payload: stravaganza.NewBuilder("bind").WithAttribute(stravaganza.Namespace, "urn:other").Build(),
+		},
+		{
+			name:   "InvalidResource",
+			iqType: stravaganza.SetType,
+			payload: stravaganza.NewBuilder("bind").
+				WithAttribute(stravaganza.Namespace, Namespace).
+				WithChild(stravaganza.NewBuilder("resource").WithText("\u0007").Build()).
+				Build(),
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			iq, err := stravaganza.NewIQBuilder().
+				WithAttribute(stravaganza.ID, "1").
+				WithAttribute(stravaganza.Type, tc.iqType).
+				WithAttribute(stravaganza.From, "juliet@im.example.com").
+				WithAttribute(stravaganza.To, "im.example.com").
+				WithChild(tc.payload).
+				BuildIQ()
+			require.Nil(t, err)
+
+			_, err = ParseRequest(iq)
+
+			var se *stanzaerror.Error
+			require.True(t, errors.As(err, &se))
+			require.Equal(t, stanzaerror.BadRequest, se.Reason)
+			require.Equal(t, iq, se.SentElement)
+		})
+	}
+}
+
+func TestBind_Result(t *testing.T) {
+	// given
+	from, _ := jid.NewWithString("juliet@im.example.com", false)
+	to, _ := jid.NewWithString("im.example.com", false)
+	bound, _ := jid.NewWithString("juliet@im.example.com/4db06f06-1ea4-11dc-aca3-000bcd821bfb", false)
+	iq, _ := NewRequest("yhc13a95", from, to, "")
+
+	// when
+	result, err := NewResult(iq, bound)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, `<iq id='yhc13a95' type='result' from='im.example.com' to='juliet@im.example.com'>`+
+		`<bind xmlns='urn:ietf:params:xml:ns:xmpp-bind'><jid>juliet@im.example.com/4db06f06-1ea4-11dc-aca3-000bcd821bfb</jid></bind></iq>`,
+		result.String())
+
+	j, err := ParseResult(result)
+	require.Nil(t, err)
+	require.Equal(t, bound.String(), j.String())
+
+	_, err = NewResult(iq, from)
+	require.NotNil(t, err)
+}
+
+func TestBind_ErrorResult(t *testing.T) {
+	// given
+	from, _ := jid.NewWithString("juliet@im.example.com", false)
+	to, _ := jid.NewWithString("im.example.com", false)
+	iq, _ := NewRequest("yhc13a95", from, to, "balcony")
+
+	var tcs = []struct {
+		name   string
+		err    *stanzaerror.Error
+		reason stanzaerror.Reason
+	}{
+		{name: "Conflict", err: ConflictError(iq), reason: stanzaerror.Conflict},
+		{name: "NotAllowed", err: NotAllowedError(iq), reason: stanzaerror.NotAllowed},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			errIQ, err := stravaganza.NewBuilderFromElement(tc.err.Element()).BuildIQ()
+			require.Nil(t, err)
+			_, err = ParseResult(errIQ)
+
+			// then
+			var se *stanzaerror.Error
+			require.True(t, errors.As(err, &se))
+			require.Equal(t, tc.reason, se.Reason)
+		})
+	}
+}
@@ -17,16 +17,21 @@ package stravaganza
 import (
 	"errors"
 	"fmt"
-
-	"github.com/golang/protobuf/proto"
 )
 
 // Builder builds generic XML node elements.
+//
+// A Builder never modifies an already built element, nor the element it was derived from.
+// Attributes, sub elements and text nodes are shared with those elements until the builder
+// gets modified, at which point they are copied (copy-on-write).
 type Builder struct {
 	attrs        []*PBAttribute
 	elements     []*PBElement
+	textNodes    []*PBTextNode
 	name, text   string
+	namespace    string
 	validateJIDs bool
+	shared       bool
 }
 
 // NewBuilder returns a name initialized builder instance.
@@ -49,41 +54,58 @@ func NewIQBuilder() *Builder {
 	return NewBuilder("iq")
 }
 
-// NewBuilderFromElement returns an element builder derived from a copied element.
+// NewBuilderFromElement returns an element builder derived from element.
+// Derived builder modifications are never reflected into the original element.
 func NewBuilderFromElement(element Element) *Builder {
 	if element == nil {
 		return &Builder{}
 	}
 	protoFrom := element.Proto()
 	return &Builder{
-		name:     protoFrom.GetName(),
-		text:     protoFrom.GetText(),
-		attrs:    copyProtoAttributes(protoFrom.GetAttributes()),
-		elements: protoFrom.GetElements(),
+		name:      protoFrom.GetName(),
+		text:      protoFrom.GetText(),
+		namespace: protoFrom.GetNamespace(),
+		attrs:     protoFrom.GetAttributes(),
+		elements:  protoFrom.GetElements(),
+		textNodes: protoFrom.GetTextNodes(),
+		shared:    true,
 	}
 }
 
 // NewBuilderFromBinary returns an element builder derived from an element binary representation.
+// Both compact and legacy protobuf binary representations are accepted.
 func NewBuilderFromBinary(b []byte) (*Builder, error) {
-	var protoFrom PBElement
-	if err := proto.Unmarshal(b, &protoFrom); err != nil {
+	if isCompactBinary(b) {
+		protoFrom, err := DefaultCodec.decodeProto(b)
+		if err != nil {
+			return nil, err
+		}
+		return NewBuilderFromProto(protoFrom), nil
+	}
+	protoFrom, err := unmarshalProto(b)
+	if err != nil {
 		return nil, err
 	}
 	return &Builder{
-		name:     protoFrom.GetName(),
-		text:     protoFrom.GetText(),
-		attrs:    protoFrom.GetAttributes(),
-		elements: protoFrom.GetElements(),
+		name:      protoFrom.GetName(),
+		text:      protoFrom.GetText(),
+		namespace: protoFrom.GetNamespace(),
+		attrs:     protoFrom.GetAttributes(),
+		elements:  protoFrom.GetElements(),
+		textNodes: protoFrom.GetTextNodes(),
 	}, nil
 }
 
 // NewBuilderFromProto returns an element builder derived from proto type.
 func NewBuilderFromProto(protoFrom *PBElement) *Builder {
 	return &Builder{
-		name:     protoFrom.GetName(),
-		text:     protoFrom.GetText(),
-		attrs:    protoFrom.GetAttributes(),
-		elements: protoFrom.GetElements(),
+		name:      protoFrom.GetName(),
+		text:      protoFrom.GetText(),
+		namespace: protoFrom.GetNamespace(),
+		attrs:     protoFrom.GetAttributes(),
+		elements:  protoFrom.GetElements(),
+		textNodes: protoFrom.GetTextNodes(),
+		shared:    true,
 	}
 }
 
@@ -95,9 +117,10 @@ func (b *Builder) WithName(name string) *Builder {
 
 // WithAttribute sets an XML node attribute (label=value).
 func (b *Builder) WithAttribute(label, value string) *Builder {
+	b.own()
 	for i, pbAttr := range b.attrs {
 		if pbAttr.Label == label {
-			b.attrs[i].Value = value
+			b.attrs[i] = &PBAttribute{Label: label, Value: value}
 			return b
 		}
 	}
@@ -115,6 +138,7 @@ func (b *Builder) WithAttributes(attributes ...Attribute) *Builder {
 
 // WithoutAttribute removes an XML node attribute.
 func (b *Builder) WithoutAttribute(label string) *Builder {
+	b.own()
 	for i, pbAttr := range b.attrs {
 		if pbAttr.Label == label {
 			b.attrs = append(b.attrs[:i], b.attrs[i+1:]...)
@@ -126,12 +150,14 @@ func (b *Builder) WithoutAttribute(label string) *Builder {
 
 // WithChild appends a new sub element.
 func (b *Builder) WithChild(child Element) *Builder {
+	b.own()
 	b.elements = append(b.elements, child.Proto())
 	return b
 }
 
 // WithChildren appends all new sub elements.
 func (b *Builder) WithChildren(children ...Element) *Builder {
+	b.own()
 	for _, child := range children {
 		b.elements = append(b.elements, child.Proto())
 	}
@@ -140,31 +166,59 @@ func (b *Builder) WithChildren(children ...Element) *Builder {
 
 // WithoutChildren removes all elements with a given name.
 func (b *Builder) WithoutChildren(name string) *Builder {
-	filtered := b.elements[:0]
-	for _, pbElem := range b.elements {
-		if pbElem.Name != name {
-			filtered = append(filtered, pbElem)
-		}
-	}
-	b.elements = filtered
+	b.removeElements(func(pbElem *PBElement) bool {
+		return pbElem.Name == name
+	})
 	return b
 }
 
 // WithoutChildrenNamespace removes all elements with a given name and namespace.
 func (b *Builder) WithoutChildrenNamespace(name, ns string) *Builder {
-	filtered := b.elements[:0]
-	for _, pbElem := range b.elements {
-		if pbElem.Name != name || getProtoElementAttribute(pbElem, xmlNamespace) != ns {
-			filtered = append(filtered, pbElem)
-		}
-	}
-	b.elements = filtered
+	b.removeElements(func(pbElem *PBElement) bool {
+		return matchesProtoElementNamespace(pbElem, name, ns)
+	})
 	return b
 }
 
 // WithText sets XML node text value.
+// Any previously appended text node is replaced.
 func (b *Builder) WithText(text string) *Builder {
 	b.text = text
+	b.textNodes = nil
+	return b
+}
+
+// AppendText appends a text node right after all currently added sub elements.
+// Useful to build mixed content elements, in which text is interleaved with sub elements.
+func (b *Builder) AppendText(text string) *Builder {
+	if len(text) == 0 {
+		return b
+	}
+	b.own()
+	pos := uint32(len(b.elements))
+	if len(b.textNodes) == 0 {
+		if pos == 0 {
+			b.text += text
+			return b
+		}
+		if len(b.text) > 0 {
+			b.textNodes = append(b.textNodes, &PBTextNode{Position: 0, Value: b.text})
+		}
+	}
+	if n := len(b.textNodes); n > 0 && b.textNodes[n-1].Position == pos {
+		b.textNodes[n-1] = &PBTextNode{Position: pos, Value: b.textNodes[n-1].Value + text}
+	} else {
+		b.textNodes = append(b.textNodes, &PBTextNode{Position: pos, Value: text})
+	}
+	b.text += text
+	return b
+}
+
+// WithNamespaceURI sets XML node resolved namespace URI.
+// Usually set by namespace aware parsers, if not set element namespace URI will be derived
+// from its own namespace declarations.
+func (b *Builder) WithNamespaceURI(ns string) *Builder {
+	b.namespace = ns
 	return b
 }
 
@@ -289,23 +343,61 @@ func EmptyElement() Element {
 }
 
 func (b *Builder) buildProtoElement() *PBElement {
+	b.shared = true
 	return &PBElement{
 		Name:       b.name,
 		Attributes: b.attrs,
 		Elements:   b.elements,
 		Text:       b.text,
+		Namespace:  b.namespace,
+		TextNodes:  b.textNodes,
+	}
+}
+
+func (b *Builder) removeElements(remove func(pbElem *PBElement) bool) {
+	b.own()
+
+	// positions maps every sub element position to its position after removal
+	positions := make([]uint32, len(b.elements)+1)
+
+	filtered := b.elements[:0]
+	for i, pbElem := range b.elements {
+		positions[i] = uint32(len(filtered))
+		if !remove(pbElem) {
+			filtered = append(filtered, pbElem)
+		}
+	}
+	positions[len(b.elements)] = uint32(len(filtered))
+	b.elements = filtered
+
+	if len(b.textNodes) == 0 {
+		return
+	}
+	textNodes := make([]*PBTextNode, 0, len(b.textNodes))
+	for _, textNode := range b.textNodes {
+		pos := positions[textNode.Position]
+		if n := len(textNodes); n > 0 && textNodes[n-1].Position == pos {
+			textNodes[n-1] = &PBTextNode{Position: pos, Value: textNodes[n-1].Value + textNode.Value}
+			continue
+		}
+		textNodes = append(textNodes, &PBTextNode{Position: pos, Value: textNode.Value})
+	}
+	if len(textNodes) == 1 && textNodes[0].Position == 0 {
+		textNodes = nil // plain text content
 	}
+	b.textNodes = textNodes
 }
 
-func copyProtoAttributes(pbAttrs []*PBAttribute) []*PBAttribute {
-	cp := make([]*PBAttribute, len(pbAttrs))
-	for i, pbAttr := range pbAttrs {
-		var cpAttr PBAttribute
-		cpAttr.Label = pbAttr.Label
-		cpAttr.Value = pbAttr.Value
-		cp[i] = &cpAttr
+// own makes sure builder slices are not shared with any other element before modifying them.
+// Note that referenced attributes, sub elements and text nodes are never modified in place.
+func (b *Builder) own() {
+	if !b.shared {
+		return
 	}
-	return cp
+	b.attrs = append([]*PBAttribute(nil), b.attrs...)
+	b.elements = append([]*PBElement(nil), b.elements...)
+	b.textNodes = append([]*PBTextNode(nil), b.textNodes...)
+	b.shared = false
 }
 
 func isIQType(tp string) bool {
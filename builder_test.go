@@ -15,6 +15,8 @@
 package stravaganza
 
 import (
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -142,3 +144,118 @@ func TestBuilder_WithoutChildrenNamespace(t *testing.T) {
 	require.Nil(t, el1.Child("n1"))
 	require.Equal(t, 1, el1.ChildrenCount())
 }
+
+func TestBuilder_AppendText(t *testing.T) {
+	el := NewBuilder("body").
+		AppendText("hello ").
+		WithChild(NewBuilder("b").WithText("bold").Build()).
+		AppendText(" world").
+		Build()
+
+	require.Equal(t, "hello  world", el.Text())
+	require.Equal(t, "<body>hello <b>bold</b> world</body>", el.String())
+
+	el2 := NewBuilder("body").
+		AppendText("hello").
+		AppendText(" world").
+		Build()
+
+	require.Equal(t, "hello world", el2.Text())
+	require.Len(t, el2.Proto().GetTextNodes(), 0)
+}
+
+func TestBuilder_WithoutChildrenMixedContent(t *testing.T) {
+	el1 := NewBuilder("p").
+		AppendText("a").
+		WithChild(NewBuilder("b").Build()).
+		AppendText("c").
+		WithChild(NewBuilder("i").Build()).
+		AppendText("e").
+		Build()
+
+	el2 := NewBuilderFromElement(el1).
+		WithoutChildren("b").
+		Build()
+
+	require.Equal(t, "<p>ac<i/>e</p>", el2.String())
+	require.Equal(t, "ace", el2.Text())
+
+	el3 := NewBuilderFromElement(el2).
+		WithoutChildren("i").
+		Build()
+
+	require.Equal(t, "<p>ace</p>", el3.String())
+	require.Len(t, el3.Proto().GetTextNodes(), 0)
+}
+
+func TestBuilder_DerivedBuilderDoesNotModifySource(t *testing.T) {
+	src := NewBuilder("message").
+		WithAttribute("id", "1234").
+		WithAttribute("type", "chat").
+		WithChild(NewBuilder("body").WithText("hi").Build()).
+		WithChild(NewBuilder("thread").Build()).
+		WithChild(NewBuilder("active").Build()).
+		Build()
+	srcXML := src.String()
+
+	b := NewBuilderFromElement(src)
+	el1 := b.WithAttribute("id", "5678").
+		WithoutAttribute("type").
+		WithoutChildren("body").
+		WithChild(NewBuilder("x").Build()).
+		AppendText("text").
+		Build()
+
+	el2 := b.WithAttribute("id", "9012").
+		WithoutChildren("thread").
+		Build()
+
+	require.Equal(t, srcXML, src.String())
+	require.Equal(t, "<message id='5678'><thread/><active/><x/>text</message>", el1.String())
+	require.Equal(t, "<message id='9012'><active/><x/>text</message>", el2.String())
+}
+
+func TestBuilder_ConcurrentDerivedBuilders(t *testing.T) {
+	src := NewBuilder("iq").
+		WithAttribute("id", "1234").
+		WithAttribute("type", "result").
+		WithChild(NewBuilder("query").WithAttribute(xmlNamespace, "jabber:iq:roster").Build()).
+		WithChild(NewBuilder("x").Build()).
+		Build()
+	srcXML := src.String()
+
+	// require must not be called from spawned goroutines
+	mismatches := make([]int, 8)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if src.String() != srcXML || src.Attribute("id") != "1234" || len(src.AllChildren()) != 2 {
+					mismatches[i]++
+				}
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				b := NewBuilderFromElement(src)
+				_ = b.WithAttribute("id", strconv.Itoa(i)).
+					WithoutAttribute("type").
+					WithoutChildren("x").
+					WithChild(NewBuilder("y").Build()).
+					AppendText("text").
+					Build()
+				_ = b.WithoutChildrenNamespace("query", "jabber:iq:roster").
+					WithText("text").
+					Build()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, make([]int, 8), mismatches)
+	require.Equal(t, srcXML, src.String())
+}
@@ -0,0 +1,233 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stravaganza
+
+import (
+	"hash"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+func (e *element) ToCanonicalXML(w io.Writer) error {
+	cw := &c14nWriter{w: w}
+	cw.writeElement(e.pb)
+	return cw.err
+}
+
+func (e *element) Digest(h hash.Hash) []byte {
+	h.Reset()
+	_ = e.ToCanonicalXML(h)
+	return h.Sum(nil)
+}
+
+// ToCanonicalXML serializes el following Exclusive XML Canonicalization rules.
+func ToCanonicalXML(w io.Writer, el Element) error {
+	return protoElement(el).ToCanonicalXML(w)
+}
+
+// Digest returns the h hash sum of el canonical XML representation.
+// h is reset before being written.
+func Digest(el Element, h hash.Hash) []byte {
+	return protoElement(el).Digest(h)
+}
+
+type nsBinding struct {
+	prefix string
+	uri    string
+}
+
+type c14nAttribute struct {
+	label string
+	local string
+	uri   string
+	value string
+}
+
+// c14nWriter serializes elements following Exclusive XML Canonicalization rules.
+type c14nWriter struct {
+	w   io.Writer
+	err error
+
+	srcScope []nsBinding // namespace declarations found in the element tree
+	outScope []nsBinding // namespace declarations already rendered
+}
+
+func (cw *c14nWriter) writeElement(pb *PBElement) {
+	srcMark, outMark := len(cw.srcScope), len(cw.outScope)
+	defer func() {
+		cw.srcScope = cw.srcScope[:srcMark]
+		cw.outScope = cw.outScope[:outMark]
+	}()
+
+	var attrs []c14nAttribute
+	for _, attr := range pb.GetAttributes() {
+		switch {
+		case attr.Label == xmlNamespace:
+			cw.srcScope = append(cw.srcScope, nsBinding{uri: attr.Value})
+		case strings.HasPrefix(attr.Label, xmlNamespace+":"):
+			cw.srcScope = append(cw.srcScope, nsBinding{prefix: attr.Label[len(xmlNamespace)+1:], uri: attr.Value})
+		default:
+			attrs = append(attrs, c14nAttribute{label: attr.Label, value: attr.Value})
+		}
+	}
+	// resolve element and attribute namespaces
+	prefix, _ := splitName(pb.GetName())
+	uri, ok := cw.lookupSource(prefix)
+	if !ok || (len(prefix) == 0 && len(uri) == 0) {
+		uri = pb.GetNamespace()
+	}
+	var decls []nsBinding
+	cw.visiblyUtilize(&decls, prefix, uri)
+
+	for i := range attrs {
+		attrPrefix, local := splitName(attrs[i].label)
+		attrs[i].local = local
+		if len(attrPrefix) == 0 {
+			continue
+		}
+		attrs[i].uri, _ = cw.lookupSource(attrPrefix)
+		cw.visiblyUtilize(&decls, attrPrefix, attrs[i].uri)
+	}
+	sort.Slice(decls, func(i, j int) bool { return decls[i].prefix < decls[j].prefix })
+	sort.SliceStable(attrs, func(i, j int) bool {
+		if attrs[i].uri != attrs[j].uri {
+			return attrs[i].uri < attrs[j].uri
+		}
+		return attrs[i].local < attrs[j].local
+	})
+
+	cw.writeString("<")
+	cw.writeString(pb.GetName())
+	for _, decl := range decls {
+		if len(decl.prefix) == 0 {
+			cw.writeString(" xmlns=\"")
+		} else {
+			cw.writeString(" xmlns:")
+			cw.writeString(decl.prefix)
+			cw.writeString("=\"")
+		}
+		cw.escape(decl.uri, true)
+		cw.writeString("\"")
+	}
+	cw.outScope = append(cw.outScope, decls...)
+
+	for _, attr := range attrs {
+		cw.writeString(" ")
+		cw.writeString(attr.label)
+		cw.writeString("=\"")
+		cw.escape(attr.value, true)
+		cw.writeString("\"")
+	}
+	cw.writeString(">")
+
+	for _, node := range (&element{pb: pb}).Nodes() {
+		if node.IsText() {
+			cw.escape(node.Text, false)
+			continue
+		}
+		cw.writeElement(node.Element.Proto())
+	}
+	cw.writeString("</")
+	cw.writeString(pb.GetName())
+	cw.writeString(">")
+}
+
+// visiblyUtilize appends a namespace declaration to decls in case prefix binding
+// was not already rendered by an output ancestor.
+func (cw *c14nWriter) visiblyUtilize(decls *[]nsBinding, prefix, uri string) {
+	if prefix == xmlPrefix {
+		return
+	}
+	if len(prefix) > 0 && len(uri) == 0 {
+		return // unbound prefix
+	}
+	for _, decl := range *decls {
+		if decl.prefix == prefix {
+			return
+		}
+	}
+	rendered, ok := cw.lookupOutput(prefix)
+	if len(prefix) == 0 && len(uri) == 0 {
+		if !ok || len(rendered) == 0 {
+			return
+		}
+	} else if ok && rendered == uri {
+		return
+	}
+	*decls = append(*decls, nsBinding{prefix: prefix, uri: uri})
+}
+
+func (cw *c14nWriter) lookupSource(prefix string) (string, bool) {
+	if prefix == xmlPrefix {
+		return xmlPrefixNamespace, true
+	}
+	return lookupBinding(cw.srcScope, prefix)
+}
+
+func (cw *c14nWriter) lookupOutput(prefix string) (string, bool) {
+	return lookupBinding(cw.outScope, prefix)
+}
+
+func lookupBinding(scope []nsBinding, prefix string) (string, bool) {
+	for i := len(scope) - 1; i >= 0; i-- {
+		if scope[i].prefix == prefix {
+			return scope[i].uri, true
+		}
+	}
+	return "", false
+}
+
+func (cw *c14nWriter) writeString(s string) {
+	if cw.err != nil {
+		return
+	}
+	_, cw.err = io.WriteString(cw.w, s)
+}
+
+// escape writes s applying C14N escaping rules for text nodes or attribute values.
+func (cw *c14nWriter) escape(s string, attrValue bool) {
+	last := 0
+	for i := 0; i < len(s); {
+		r, width := utf8.DecodeRuneInString(s[i:])
+		i += width
+		var esc string
+		switch {
+		case r == '&':
+			esc = "&amp;"
+		case r == '<':
+			esc = "&lt;"
+		case r == '>' && !attrValue:
+			esc = "&gt;"
+		case r == '"' && attrValue:
+			esc = "&quot;"
+		case r == '\t' && attrValue:
+			esc = "&#x9;"
+		case r == '\n' && attrValue:
+			esc = "&#xA;"
+		case r == '\r':
+			esc = "&#xD;"
+		case !isInCharacterRange(r) || (r == utf8.RuneError && width == 1):
+			esc = "\uFFFD"
+		default:
+			continue
+		}
+		cw.writeString(s[last : i-width])
+		cw.writeString(esc)
+		last = i
+	}
+	cw.writeString(s[last:])
+}
@@ -0,0 +1,130 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stravaganza
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestElement_ToCanonicalXML(t *testing.T) {
+	var tcs = []struct {
+		name     string
+		el       Element
+		expected string
+	}{
+		{
+			name: "sorted attributes and explicit end tags",
+			el: NewBuilder("iq").
+				WithAttribute(xmlNamespace, "jabber:client").
+				WithAttribute("type", "get").
+				WithAttribute("id", "1").
+				WithChild(
+					NewBuilder("query").
+						WithAttribute(xmlNamespace, "jabber:iq:roster").
+						WithAttribute("ver", "").
+						Build(),
+				).
+				Build(),
+			expected: `<iq xmlns="jabber:client" id="1" type="get"><query xmlns="jabber:iq:roster" ver=""></query></iq>`,
+		},
+		{
+			name: "superfluous namespace declarations",
+			el: NewBuilder("message").
+				WithAttribute(xmlNamespace, "jabber:client").
+				WithChild(
+					NewBuilder("body").
+						WithAttribute(xmlNamespace, "jabber:client").
+						WithText("hi").
+						Build(),
+				).
+				Build(),
+			expected: `<message xmlns="jabber:client"><body>hi</body></message>`,
+		},
+		{
+			name: "unused prefixes and namespaced attributes",
+			el: NewBuilder("stream:features").
+				WithAttribute("xmlns:stream", "http://etherx.jabber.org/streams").
+				WithAttribute("xmlns:unused", "urn:unused").
+				WithAttribute("xmlns:a", "urn:a").
+				WithChild(
+					NewBuilder("b").
+						WithAttribute("z", "2").
+						WithAttribute("a:attr", "1").
+						WithAttribute("xml:lang", "en").
+						WithAttribute("b", `"<&>'`+"\t\n").
+						Build(),
+				).
+				Build(),
+			expected: `<stream:features xmlns:stream="http://etherx.jabber.org/streams"><b xmlns:a="urn:a" b="&quot;&lt;&amp;>'&#x9;&#xA;" z="2" xml:lang="en" a:attr="1"></b></stream:features>`,
+		},
+		{
+			name: "default namespace undeclaration",
+			el: NewBuilder("a").
+				WithAttribute(xmlNamespace, "urn:a").
+				WithChild(NewBuilder("b").WithAttribute(xmlNamespace, "").Build()).
+				Build(),
+			expected: `<a xmlns="urn:a"><b xmlns=""></b></a>`,
+		},
+		{
+			name: "resolved namespaces and mixed content",
+			el: NewBuilder("p").
+				WithNamespaceURI("http://www.w3.org/1999/xhtml").
+				AppendText("a > b\r").
+				WithChild(NewBuilder("br").WithNamespaceURI("http://www.w3.org/1999/xhtml").Build()).
+				AppendText("c").
+				Build(),
+			expected: `<p xmlns="http://www.w3.org/1999/xhtml">a &gt; b&#xD;<br></br>c</p>`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			require.Nil(t, ToCanonicalXML(buf, tc.el))
+			require.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestElement_Digest(t *testing.T) {
+	// given
+	el1 := NewBuilder("message").
+		WithAttribute("id", "1234").
+		WithAttribute("type", "chat").
+		WithChild(NewBuilder("body").WithAttribute(xmlNamespace, "jabber:client").WithText("hi").Build()).
+		Build()
+	el2 := NewBuilder("message").
+		WithAttribute("type", "chat").
+		WithAttribute("id", "1234").
+		WithChild(NewBuilder("body").WithNamespaceURI("jabber:client").WithText("hi").Build()).
+		Build()
+	el3 := NewBuilderFromElement(el1).
+		WithAttribute("type", "normal").
+		Build()
+
+	// when
+	h := sha256.New()
+	d1 := Digest(el1, h)
+	d2 := Digest(el2, h)
+	d3 := Digest(el3, h)
+
+	// then
+	require.Len(t, d1, sha256.Size)
+	require.Equal(t, d1, d2)
+	require.NotEqual(t, d1, d3)
+}
@@ -0,0 +1,450 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stravaganza
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// CodecVersion is the leading byte of every compact binary encoded element.
+// Any value lower than 0x08 can never be the first byte of a protobuf encoded message,
+// given that it would represent the invalid field number 0. This allows telling apart
+// compact and legacy protobuf binary representations.
+const CodecVersion byte = 0x01
+
+const maxCodecDepth = 1024
+
+// pbElementsFieldNumber is the PBElement 'elements' field number.
+const pbElementsFieldNumber = 3
+
+var (
+	// ErrDictionaryMismatch will be returned when decoding an element encoded with a different dictionary.
+	ErrDictionaryMismatch = errors.New("stravaganza: codec dictionary mismatch")
+
+	errCodecTruncated = errors.New("stravaganza: truncated binary element")
+	errCodecTooDeep   = errors.New("stravaganza: binary element exceeds maximum depth")
+)
+
+// DefaultCodec is the codec used to decode compact binary representations in element UnmarshalBinary
+// method and NewBuilderFromBinary. Its dictionary contains the most common XMPP element names,
+// attribute labels and namespaces.
+// Element MarshalBinary keeps emitting the protobuf representation, so that peers unaware of
+// the compact format are still able to decode it. Compact encoding is opted in by means of DefaultCodec.Encode.
+var DefaultCodec = NewCodec(defaultDictionary)
+
+var defaultDictionary = []string{
+	// element names
+	"iq", "message", "presence", "body", "subject", "thread", "error", "text", "query",
+	"show", "status", "priority", "x", "c", "delay", "ping", "active", "composing",
+	"paused", "inactive", "gone", "request", "received", "markable", "displayed",
+	"stanza-id", "origin-id", "item", "items", "pubsub", "event", "forwarded", "result",
+
+	// attribute labels
+	"id", "type", "from", "to", "xmlns", "xml:lang", "node", "jid", "name", "by",
+	"stamp", "hash", "ver", "code", "subscription", "category",
+
+	// namespaces
+	"jabber:client",
+	"jabber:server",
+	"jabber:iq:roster",
+	"jabber:iq:version",
+	"jabber:x:data",
+	"vcard-temp",
+	"urn:xmpp:ping",
+	"urn:xmpp:delay",
+	"urn:xmpp:receipts",
+	"urn:xmpp:chat-markers:0",
+	"urn:xmpp:sid:0",
+	"urn:xmpp:mam:2",
+	"urn:xmpp:carbons:2",
+	"urn:xmpp:forward:0",
+	"urn:xmpp:hints",
+	"urn:ietf:params:xml:ns:xmpp-stanzas",
+	"http://jabber.org/protocol/chatstates",
+	"http://jabber.org/protocol/caps",
+	"http://jabber.org/protocol/disco#info",
+	"http://jabber.org/protocol/disco#items",
+	"http://jabber.org/protocol/pubsub",
+	"http://jabber.org/protocol/pubsub#event",
+}
+
+// Codec encodes and decodes elements using a compact binary format.
+//
+// Element names, attribute labels and namespace values are interned, so that every
+// repeated occurrence is encoded as a small index. Interned strings are looked up first
+// into the codec dictionary, which is never transmitted and must be shared between peers.
+// An encoded element carries its dictionary fingerprint, so that decoding with
+// a different dictionary fails.
+//
+// A Codec is safe to use from multiple goroutines.
+type Codec struct {
+	dict        []string
+	dictIndex   map[string]int
+	fingerprint uint32
+
+	pool sync.Pool
+}
+
+// NewCodec returns a new codec using dictionary for string interning.
+// Dictionary must not be modified once the codec is created.
+func NewCodec(dictionary []string) *Codec {
+	c := &Codec{
+		dict:      dictionary,
+		dictIndex: make(map[string]int, len(dictionary)),
+	}
+	h := fnv.New32a()
+	for i, s := range dictionary {
+		if _, ok := c.dictIndex[s]; !ok {
+			c.dictIndex[s] = i
+		}
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+	if len(dictionary) > 0 {
+		c.fingerprint = h.Sum32()
+	}
+	c.pool.New = func() interface{} {
+		return &codecEncoder{strings: make(map[string]int)}
+	}
+	return c
+}
+
+// Fingerprint returns the codec dictionary fingerprint.
+func (c *Codec) Fingerprint() uint32 {
+	return c.fingerprint
+}
+
+// Encode returns el compact binary representation.
+func (c *Codec) Encode(el Element) []byte {
+	return c.AppendEncode(nil, el)
+}
+
+// AppendEncode appends el compact binary representation to dst and returns the extended buffer.
+func (c *Codec) AppendEncode(dst []byte, el Element) []byte {
+	enc := c.pool.Get().(*codecEncoder)
+	enc.c = c
+	enc.buf = dst
+	enc.buf = append(enc.buf, CodecVersion)
+	enc.buf = binary.AppendUvarint(enc.buf, uint64(c.fingerprint))
+	enc.encodeElement(el.Proto())
+
+	b := enc.buf
+	enc.reset()
+	c.pool.Put(enc)
+	return b
+}
+
+// Decode decodes an element from its compact binary representation.
+func (c *Codec) Decode(b []byte) (Element, error) {
+	pb, err := c.decodeProto(b)
+	if err != nil {
+		return nil, err
+	}
+	return &element{pb: pb}, nil
+}
+
+func (c *Codec) decodeProto(b []byte) (*PBElement, error) {
+	if len(b) == 0 || b[0] != CodecVersion {
+		return nil, errors.New("stravaganza: unknown binary element version")
+	}
+	// decoded strings share a single copy of the input
+	dec := &codecDecoder{c: c, buf: string(b[1:])}
+	fp, err := dec.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if uint32(fp) != c.fingerprint || fp > 0xffffffff {
+		return nil, ErrDictionaryMismatch
+	}
+	pb, err := dec.decodeElement(0)
+	if err != nil {
+		return nil, err
+	}
+	if len(dec.buf) > 0 {
+		return nil, fmt.Errorf("stravaganza: %d trailing bytes after binary element", len(dec.buf))
+	}
+	return pb, nil
+}
+
+func isCompactBinary(b []byte) bool {
+	return len(b) > 0 && b[0] < 0x08
+}
+
+// unmarshalProto decodes an element from its protobuf binary representation.
+// Given that UnmarshalVT recursion is unbounded, sub element nesting is checked up front
+// against the same limit applied to compact binary representations.
+func unmarshalProto(b []byte) (*PBElement, error) {
+	if err := checkProtoDepth(b); err != nil {
+		return nil, err
+	}
+	var pb PBElement
+	if err := pb.UnmarshalVT(b); err != nil {
+		return nil, err
+	}
+	return &pb, nil
+}
+
+// checkProtoDepth scans a protobuf encoded PBElement, without recursion, making sure
+// its sub elements don't nest beyond maxCodecDepth levels.
+func checkProtoDepth(b []byte) error {
+	var ends []int // end offsets of currently open sub elements
+	for off := 0; off < len(b); {
+		for len(ends) > 0 && off >= ends[len(ends)-1] {
+			ends = ends[:len(ends)-1]
+		}
+		num, typ, n := protowire.ConsumeTag(b[off:])
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		off += n
+		if num == pbElementsFieldNumber && typ == protowire.BytesType {
+			l, n := protowire.ConsumeVarint(b[off:])
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			off += n
+			if l > uint64(len(b)-off) {
+				return errCodecTruncated
+			}
+			ends = append(ends, off+int(l))
+			if len(ends) > maxCodecDepth {
+				return errCodecTooDeep
+			}
+			continue
+		}
+		n = protowire.ConsumeFieldValue(num, typ, b[off:])
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		off += n
+	}
+	return nil
+}
+
+type codecEncoder struct {
+	c       *Codec
+	buf     []byte
+	strings map[string]int
+}
+
+func (enc *codecEncoder) reset() {
+	enc.c = nil
+	enc.buf = nil
+	for k := range enc.strings {
+		delete(enc.strings, k)
+	}
+}
+
+func (enc *codecEncoder) encodeElement(pb *PBElement) {
+	enc.internString(pb.GetName())
+	enc.internString(pb.GetNamespace())
+
+	attrs := pb.GetAttributes()
+	enc.buf = binary.AppendUvarint(enc.buf, uint64(len(attrs)))
+	for _, attr := range attrs {
+		enc.internString(attr.Label)
+		if isNamespaceDecl(attr.Label) {
+			enc.internString(attr.Value)
+		} else {
+			enc.appendString(attr.Value)
+		}
+	}
+	enc.appendString(pb.GetText())
+
+	textNodes := pb.GetTextNodes()
+	enc.buf = binary.AppendUvarint(enc.buf, uint64(len(textNodes)))
+	for _, tn := range textNodes {
+		enc.buf = binary.AppendUvarint(enc.buf, uint64(tn.Position))
+		enc.appendString(tn.Value)
+	}
+	elements := pb.GetElements()
+	enc.buf = binary.AppendUvarint(enc.buf, uint64(len(elements)))
+	for _, child := range elements {
+		enc.encodeElement(child)
+	}
+}
+
+// internString encodes s either as a reference to a previously seen or dictionary string (index+1),
+// or as a zero followed by the string literal, which is then added to the string table.
+func (enc *codecEncoder) internString(s string) {
+	if i, ok := enc.c.dictIndex[s]; ok {
+		enc.buf = binary.AppendUvarint(enc.buf, uint64(i+1))
+		return
+	}
+	if i, ok := enc.strings[s]; ok {
+		enc.buf = binary.AppendUvarint(enc.buf, uint64(len(enc.c.dict)+i+1))
+		return
+	}
+	enc.strings[s] = len(enc.strings)
+	enc.buf = append(enc.buf, 0)
+	enc.appendString(s)
+}
+
+func (enc *codecEncoder) appendString(s string) {
+	enc.buf = binary.AppendUvarint(enc.buf, uint64(len(s)))
+	enc.buf = append(enc.buf, s...)
+}
+
+type codecDecoder struct {
+	c       *Codec
+	buf     string
+	strings []string
+}
+
+func (dec *codecDecoder) decodeElement(depth int) (*PBElement, error) {
+	if depth > maxCodecDepth {
+		return nil, errCodecTooDeep
+	}
+	var pb PBElement
+	var err error
+	if pb.Name, err = dec.internedString(); err != nil {
+		return nil, err
+	}
+	if pb.Namespace, err = dec.internedString(); err != nil {
+		return nil, err
+	}
+	n, err := dec.count()
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		pb.Attributes = make([]*PBAttribute, n)
+		for i := range pb.Attributes {
+			var attr PBAttribute
+			if attr.Label, err = dec.internedString(); err != nil {
+				return nil, err
+			}
+			if isNamespaceDecl(attr.Label) {
+				attr.Value, err = dec.internedString()
+			} else {
+				attr.Value, err = dec.string()
+			}
+			if err != nil {
+				return nil, err
+			}
+			pb.Attributes[i] = &attr
+		}
+	}
+	if pb.Text, err = dec.string(); err != nil {
+		return nil, err
+	}
+	if n, err = dec.count(); err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		pb.TextNodes = make([]*PBTextNode, n)
+		for i := range pb.TextNodes {
+			pos, err := dec.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			if pos > 0xffffffff {
+				return nil, errors.New("stravaganza: invalid binary text node position")
+			}
+			tn := PBTextNode{Position: uint32(pos)}
+			if tn.Value, err = dec.string(); err != nil {
+				return nil, err
+			}
+			pb.TextNodes[i] = &tn
+		}
+	}
+	if n, err = dec.count(); err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		pb.Elements = make([]*PBElement, n)
+		for i := range pb.Elements {
+			if pb.Elements[i], err = dec.decodeElement(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &pb, nil
+}
+
+func (dec *codecDecoder) internedString() (string, error) {
+	ref, err := dec.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if ref == 0 {
+		s, err := dec.string()
+		if err != nil {
+			return "", err
+		}
+		dec.strings = append(dec.strings, s)
+		return s, nil
+	}
+	i := ref - 1
+	if i < uint64(len(dec.c.dict)) {
+		return dec.c.dict[i], nil
+	}
+	i -= uint64(len(dec.c.dict))
+	if i < uint64(len(dec.strings)) {
+		return dec.strings[i], nil
+	}
+	return "", fmt.Errorf("stravaganza: invalid binary string reference: %d", ref)
+}
+
+func (dec *codecDecoder) string() (string, error) {
+	l, err := dec.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if l > uint64(len(dec.buf)) {
+		return "", errCodecTruncated
+	}
+	s := dec.buf[:l]
+	dec.buf = dec.buf[l:]
+	return s, nil
+}
+
+// count decodes a collection length, which can't be greater than the number of remaining bytes.
+func (dec *codecDecoder) count() (int, error) {
+	n, err := dec.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(dec.buf)) {
+		return 0, errCodecTruncated
+	}
+	return int(n), nil
+}
+
+func (dec *codecDecoder) uvarint() (uint64, error) {
+	var v uint64
+	for i := 0; i < len(dec.buf) && i < binary.MaxVarintLen64; i++ {
+		b := dec.buf[i]
+		if b < 0x80 {
+			if i == binary.MaxVarintLen64-1 && b > 1 {
+				break // overflow
+			}
+			dec.buf = dec.buf[i+1:]
+			return v | uint64(b)<<(7*i), nil
+		}
+		v |= uint64(b&0x7f) << (7 * i)
+	}
+	return 0, errCodecTruncated
+}
+
+func isNamespaceDecl(label string) bool {
+	return label == xmlNamespace || (len(label) > len(xmlNamespace) && label[:len(xmlNamespace)+1] == xmlNamespace+":")
+}
@@ -0,0 +1,198 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stravaganza
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCodec_RoundTrip(t *testing.T) {
+	// given
+	el := testCodecElement()
+
+	// when
+	b := DefaultCodec.Encode(el)
+	el2, err := DefaultCodec.Decode(b)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, CodecVersion, b[0])
+	require.True(t, proto.Equal(el.Proto(), el2.Proto()))
+	require.Equal(t, el.String(), el2.String())
+
+	protoBytes, _ := proto.Marshal(el.Proto())
+	require.Less(t, len(b), len(protoBytes))
+}
+
+func TestCodec_CustomDictionary(t *testing.T) {
+	// given
+	c1 := NewCodec([]string{"message", "body"})
+	c2 := NewCodec([]string{"message", "thread"})
+	c3 := NewCodec(nil)
+	el := testCodecElement()
+
+	// when
+	b := c1.Encode(el)
+	el1, err1 := c1.Decode(b)
+	_, err2 := c2.Decode(b)
+	_, err3 := c3.Decode(b)
+
+	// then
+	require.Nil(t, err1)
+	require.Equal(t, el.String(), el1.String())
+	require.Equal(t, ErrDictionaryMismatch, err2)
+	require.Equal(t, ErrDictionaryMismatch, err3)
+	require.NotEqual(t, c1.Fingerprint(), c2.Fingerprint())
+	require.Equal(t, uint32(0), c3.Fingerprint())
+}
+
+func TestCodec_Truncated(t *testing.T) {
+	b := DefaultCodec.Encode(testCodecElement())
+	for i := 0; i < len(b); i++ {
+		_, err := DefaultCodec.Decode(b[:i])
+		require.NotNil(t, err, i)
+	}
+	_, err := DefaultCodec.Decode(append(b, 0))
+	require.NotNil(t, err)
+}
+
+func TestElement_UnmarshalBinaryLegacy(t *testing.T) {
+	// given
+	el := testCodecElement()
+	protoBytes, _ := el.MarshalBinary()
+	compactBytes := DefaultCodec.Encode(el)
+
+	// when
+	el1 := EmptyElement()
+	err1 := el1.UnmarshalBinary(protoBytes)
+
+	el2 := EmptyElement()
+	err2 := el2.UnmarshalBinary(compactBytes)
+
+	b, err3 := NewBuilderFromBinary(protoBytes)
+
+	// then
+	require.Nil(t, err1)
+	require.Nil(t, err2)
+	require.Nil(t, err3)
+	require.False(t, isCompactBinary(protoBytes))
+	require.Equal(t, el.String(), el1.String())
+	require.Equal(t, el.String(), el2.String())
+	require.Equal(t, el.String(), b.Build().String())
+}
+
+func TestElement_UnmarshalBinaryMaxDepth(t *testing.T) {
+	// given
+	shallow := testNestedProtoBytes(100)
+	deep := testNestedProtoBytes(maxCodecDepth + 1)
+
+	// when
+	err1 := EmptyElement().UnmarshalBinary(shallow)
+	err2 := EmptyElement().UnmarshalBinary(deep)
+	_, err3 := NewBuilderFromBinary(deep)
+
+	// then
+	require.Nil(t, err1)
+	require.Equal(t, errCodecTooDeep, err2)
+	require.Equal(t, errCodecTooDeep, err3)
+}
+
+func testNestedProtoBytes(depth int) []byte {
+	var b []byte
+	for i := 0; i <= depth; i++ {
+		var el []byte
+		el = protowire.AppendTag(el, 1, protowire.BytesType)
+		el = protowire.AppendString(el, "a")
+		if b != nil {
+			el = protowire.AppendTag(el, pbElementsFieldNumber, protowire.BytesType)
+			el = protowire.AppendBytes(el, b)
+		}
+		b = el
+	}
+	return b
+}
+
+func testCodecElement() Element {
+	return NewBuilder("message").
+		WithAttribute(xmlNamespace, "jabber:client").
+		WithAttribute("id", "ed5c1fc8-3a2c-4f43-8a1a-5a2a1f0b7c2e").
+		WithAttribute("type", "chat").
+		WithAttribute("from", "ortuman@jackal.im/yard").
+		WithAttribute("to", "noelia@jackal.im/balcony").
+		WithChild(NewBuilder("body").WithText("Hi there!").Build()).
+		WithChild(NewBuilder("active").WithAttribute(xmlNamespace, "http://jabber.org/protocol/chatstates").Build()).
+		WithChild(NewBuilder("request").WithAttribute(xmlNamespace, "urn:xmpp:receipts").Build()).
+		WithChild(
+			NewBuilder("html").
+				WithAttribute(xmlNamespace, "http://jabber.org/protocol/xhtml-im").
+				WithChild(
+					NewBuilder("body").
+						WithAttribute(xmlNamespace, "http://www.w3.org/1999/xhtml").
+						WithNamespaceURI("http://www.w3.org/1999/xhtml").
+						AppendText("Hi ").
+						WithChild(NewBuilder("strong").WithAttribute(xmlNamespace, "http://www.w3.org/1999/xhtml").WithText("there").Build()).
+						AppendText("!").
+						Build(),
+				).
+				Build(),
+		).
+		Build()
+}
+
+func BenchmarkCodec_Encode(b *testing.B) {
+	el := testCodecElement()
+	buf := make([]byte, 0, 1024)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = DefaultCodec.AppendEncode(buf[:0], el)
+	}
+}
+
+func BenchmarkCodec_Decode(b *testing.B) {
+	data := DefaultCodec.Encode(testCodecElement())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = DefaultCodec.Decode(data)
+	}
+}
+
+func BenchmarkProto_Marshal(b *testing.B) {
+	el := testCodecElement()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = proto.Marshal(el.Proto())
+	}
+}
+
+func BenchmarkProto_Unmarshal(b *testing.B) {
+	data, _ := proto.Marshal(testCodecElement().Proto())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var pb PBElement
+		_ = proto.Unmarshal(data, &pb)
+	}
+}
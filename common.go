@@ -14,6 +14,8 @@
 
 package stravaganza
 
+import "strings"
+
 func getProtoElementAttribute(pbElement *PBElement, name string) string {
 	for _, attr := range pbElement.Attributes {
 		if attr.Label == name {
@@ -22,3 +24,46 @@ func getProtoElementAttribute(pbElement *PBElement, name string) string {
 	}
 	return ""
 }
+
+// getProtoElementNamespaceURI returns the element namespace URI.
+// In case the element namespace was not resolved at parsing time it will be
+// derived from the element own namespace declarations.
+func getProtoElementNamespaceURI(pbElement *PBElement) string {
+	if ns := pbElement.GetNamespace(); len(ns) > 0 {
+		return ns
+	}
+	prefix, _ := splitName(pbElement.GetName())
+	switch prefix {
+	case "":
+		return getProtoElementAttribute(pbElement, xmlNamespace)
+	case xmlPrefix:
+		return xmlPrefixNamespace
+	default:
+		return getProtoElementAttribute(pbElement, xmlNamespace+":"+prefix)
+	}
+}
+
+// matchesProtoElementNamespace tells whether an element matches a name and namespace pair,
+// either by its literal name and 'xmlns' attribute or by its local name and namespace URI.
+// Local name matching only applies to prefixed elements whose prefix is bound to a namespace.
+func matchesProtoElementNamespace(pbElement *PBElement, name, ns string) bool {
+	if pbElement.Name == name && getProtoElementAttribute(pbElement, xmlNamespace) == ns {
+		return true
+	}
+	prefix, local := splitName(pbElement.Name)
+	if local != name {
+		return false
+	}
+	uri := getProtoElementNamespaceURI(pbElement)
+	if len(prefix) > 0 && len(uri) == 0 {
+		return false // unbound prefix
+	}
+	return uri == ns
+}
+
+func splitName(name string) (prefix, local string) {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
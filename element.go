@@ -19,12 +19,15 @@ import (
 	"io"
 	"sync"
 
-	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
 	xmlNamespace = "xmlns"
 
+	xmlPrefix          = "xml"
+	xmlPrefixNamespace = "http://www.w3.org/XML/1998/namespace"
+
 	maxStringerBufferSize = 256 * 1024
 )
 
@@ -92,7 +95,7 @@ func (e *element) Children(name string) []Element {
 
 func (e *element) ChildNamespace(name, ns string) Element {
 	for _, pbElement := range e.pb.GetElements() {
-		if pbElement.Name == name && getProtoElementAttribute(pbElement, xmlNamespace) == ns {
+		if matchesProtoElementNamespace(pbElement, name, ns) {
 			return &element{pb: pbElement}
 		}
 	}
@@ -102,7 +105,7 @@ func (e *element) ChildNamespace(name, ns string) Element {
 func (e *element) ChildrenNamespace(name, ns string) []Element {
 	var elements []Element
 	for _, pbElement := range e.pb.GetElements() {
-		if pbElement.Name == name && getProtoElementAttribute(pbElement, xmlNamespace) == ns {
+		if matchesProtoElementNamespace(pbElement, name, ns) {
 			elements = append(elements, &element{pb: pbElement})
 		}
 	}
@@ -131,7 +134,7 @@ func (e *element) ToXML(w io.Writer, includeClosing bool) error {
 		if _, err := io.WriteString(w, "='"); err != nil {
 			return err
 		}
-		if _, err := io.WriteString(w, attr.Value); err != nil {
+		if err := escapeAttrValue(w, attr.Value); err != nil {
 			return err
 		}
 		if _, err := io.WriteString(w, "'"); err != nil {
@@ -144,15 +147,8 @@ func (e *element) ToXML(w io.Writer, includeClosing bool) error {
 		if _, err := io.WriteString(w, ">"); err != nil {
 			return err
 		}
-		if len(e.Text()) > 0 {
-			if err := escapeText(w, []byte(e.Text()), false); err != nil {
-				return err
-			}
-		}
-		for _, elem := range e.AllChildren() {
-			if err := elem.ToXML(w, true); err != nil {
-				return err
-			}
+		if err := e.contentToXML(w); err != nil {
+			return err
 		}
 
 		if includeClosing {
@@ -176,12 +172,59 @@ func (e *element) ToXML(w io.Writer, includeClosing bool) error {
 	return err
 }
 
+func (e *element) contentToXML(w io.Writer) error {
+	textNodes := e.pb.GetTextNodes()
+	if len(textNodes) == 0 {
+		if len(e.Text()) > 0 {
+			if err := escapeText(w, e.Text(), false); err != nil {
+				return err
+			}
+		}
+		for _, elem := range e.AllChildren() {
+			if err := elem.ToXML(w, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// serialize mixed content
+	pbElements := e.pb.GetElements()
+	for i := 0; i <= len(pbElements); i++ {
+		for len(textNodes) > 0 && int(textNodes[0].Position) == i {
+			if err := escapeText(w, textNodes[0].Value, false); err != nil {
+				return err
+			}
+			textNodes = textNodes[1:]
+		}
+		if i < len(pbElements) {
+			elem := &element{pb: pbElements[i]}
+			if err := elem.ToXML(w, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (e *element) MarshalBinary() (data []byte, err error) {
-	return proto.Marshal(e.pb)
+	return e.pb.MarshalVT()
 }
 
 func (e *element) UnmarshalBinary(data []byte) error {
-	return proto.Unmarshal(data, e.pb)
+	if !isCompactBinary(data) {
+		pb, err := unmarshalProto(data)
+		if err != nil {
+			return err
+		}
+		e.pb = pb
+		return nil
+	}
+	pb, err := DefaultCodec.decodeProto(data)
+	if err != nil {
+		return err
+	}
+	e.pb = pb
+	return nil
 }
 
 func (e *element) String() string {
@@ -206,10 +249,79 @@ func (e *element) Name() string {
 	return e.pb.GetName()
 }
 
+func (e *element) LocalName() string {
+	_, local := splitName(e.pb.GetName())
+	return local
+}
+
+func (e *element) NamespaceURI() string {
+	return getProtoElementNamespaceURI(e.pb)
+}
+
 func (e *element) Text() string {
 	return e.pb.GetText()
 }
 
+func (e *element) Nodes() []Node {
+	pbElements := e.pb.GetElements()
+	textNodes := e.pb.GetTextNodes()
+	if len(textNodes) == 0 {
+		nodes := make([]Node, 0, len(pbElements)+1)
+		if len(e.Text()) > 0 {
+			nodes = append(nodes, Node{Text: e.Text()})
+		}
+		for _, pbElement := range pbElements {
+			nodes = append(nodes, Node{Element: &element{pb: pbElement}})
+		}
+		return nodes
+	}
+	nodes := make([]Node, 0, len(pbElements)+len(textNodes))
+	for i := 0; i <= len(pbElements); i++ {
+		for len(textNodes) > 0 && int(textNodes[0].Position) == i {
+			nodes = append(nodes, Node{Text: textNodes[0].Value})
+			textNodes = textNodes[1:]
+		}
+		if i < len(pbElements) {
+			nodes = append(nodes, Node{Element: &element{pb: pbElements[i]}})
+		}
+	}
+	return nodes
+}
+
+func (e *element) DeepCopy() Element {
+	return &element{pb: proto.Clone(e.pb).(*PBElement)}
+}
+
+// LocalName returns el XML node name without its namespace prefix.
+func LocalName(el Element) string {
+	return protoElement(el).LocalName()
+}
+
+// NamespaceURI returns el XML node namespace URI.
+// In case it was not resolved at parsing time it's derived from el own namespace declarations.
+func NamespaceURI(el Element) string {
+	return protoElement(el).NamespaceURI()
+}
+
+// Nodes returns all el XML node text and sub element nodes in document order.
+func Nodes(el Element) []Node {
+	return protoElement(el).Nodes()
+}
+
+// DeepCopy returns a copy of el that doesn't share any underlying data with it.
+func DeepCopy(el Element) Element {
+	return protoElement(el).DeepCopy()
+}
+
+// protoElement returns an element backed by el protobuf message,
+// so that el needn't be implemented by this package.
+func protoElement(el Element) *element {
+	if e, ok := el.(*element); ok {
+		return e
+	}
+	return &element{pb: el.Proto()}
+}
+
 func (e *element) Proto() *PBElement {
 	return e.pb
 }
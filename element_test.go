@@ -16,6 +16,7 @@ package stravaganza
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -86,6 +87,43 @@ func TestElement_Children(t *testing.T) {
 	require.Len(t, n2s, 1)
 }
 
+func TestElement_NamespaceURI(t *testing.T) {
+	el := &element{pb: &PBElement{
+		Name: "n1",
+		Elements: []*PBElement{
+			{Name: "x:n2", Attributes: []*PBAttribute{{Label: "xmlns:x", Value: "ns2"}}},
+			{Name: "n3", Namespace: "ns3"},
+			{Name: "n4", Attributes: []*PBAttribute{{Label: xmlNamespace, Value: "ns4"}}},
+		},
+	}}
+	n2 := el.ChildNamespace("n2", "ns2")
+	require.NotNil(t, n2)
+	require.Equal(t, "n2", LocalName(n2))
+	require.Equal(t, "ns2", NamespaceURI(n2))
+
+	n3 := el.ChildNamespace("n3", "ns3")
+	require.NotNil(t, n3)
+	require.Equal(t, "ns3", NamespaceURI(n3))
+
+	n4 := el.ChildNamespace("n4", "ns4")
+	require.NotNil(t, n4)
+	require.Equal(t, "n4", LocalName(n4))
+	require.Equal(t, "ns4", NamespaceURI(n4))
+
+	require.Nil(t, el.ChildNamespace("x:n2", "ns3"))
+	require.Len(t, el.ChildrenNamespace("n2", "ns2"), 1)
+}
+
+func TestElement_NamespaceURIUnboundPrefix(t *testing.T) {
+	el := NewBuilder("stream:stream").
+		WithChild(NewBuilder("stream:error").Build()).
+		Build()
+
+	require.Nil(t, el.ChildNamespace("error", ""))
+	require.NotNil(t, el.ChildNamespace("stream:error", ""))
+	require.Len(t, el.ChildrenNamespace("error", ""), 0)
+}
+
 func TestElement_ToXML(t *testing.T) {
 	el := &element{pb: &PBElement{
 		Name:       "n1",
@@ -110,6 +148,92 @@ func TestElement_ToXML(t *testing.T) {
 	require.Equal(t, str, goStr)
 }
 
+func TestElement_Nodes(t *testing.T) {
+	el := &element{pb: &PBElement{
+		Name:     "body",
+		Elements: []*PBElement{{Name: "b", Text: "bold"}},
+		Text:     "hello  world",
+		TextNodes: []*PBTextNode{
+			{Position: 0, Value: "hello "},
+			{Position: 1, Value: " world"},
+		},
+	}}
+
+	nodes := el.Nodes()
+	require.Len(t, nodes, 3)
+
+	require.True(t, nodes[0].IsText())
+	require.Equal(t, "hello ", nodes[0].Text)
+	require.False(t, nodes[1].IsText())
+	require.Equal(t, "b", nodes[1].Element.Name())
+	require.True(t, nodes[2].IsText())
+	require.Equal(t, " world", nodes[2].Text)
+
+	require.Equal(t, "<body>hello <b>bold</b> world</body>", el.String())
+
+	el2 := &element{pb: &PBElement{
+		Name:     "n1",
+		Elements: []*PBElement{{Name: "n2"}},
+		Text:     "some text",
+	}}
+	nodes = el2.Nodes()
+	require.Len(t, nodes, 2)
+	require.Equal(t, "some text", nodes[0].Text)
+	require.Equal(t, "n2", nodes[1].Element.Name())
+}
+
+func TestElement_ToXMLEscapedAttributes(t *testing.T) {
+	el := &element{pb: &PBElement{
+		Name: "message",
+		Attributes: []*PBAttribute{
+			{Label: "from", Value: "d'artagnan@jackal.im/'><inject/>"},
+			{Label: "var", Value: "a & \"b\"\tc\nd\re"},
+		},
+	}}
+	require.Equal(t, "<message from='d&#39;artagnan@jackal.im/&#39;&gt;&lt;inject/&gt;' var='a &amp; &#34;b&#34;&#x9;c&#xA;d&#xD;e'/>", el.String())
+}
+
+func TestElement_DeepCopy(t *testing.T) {
+	el := NewBuilder("n1").
+		WithAttribute("id", "1234").
+		WithChild(NewBuilder("n2").WithText("some text").Build()).
+		Build()
+
+	cp := DeepCopy(el)
+	cp.Proto().Attributes[0].Value = "5678"
+	cp.Proto().Elements[0].Text = "other text"
+
+	require.Equal(t, "<n1 id='1234'><n2>some text</n2></n1>", el.String())
+	require.Equal(t, "<n1 id='5678'><n2>other text</n2></n1>", cp.String())
+}
+
+func TestElement_HelpersForeignImplementation(t *testing.T) {
+	// given
+	type foreignElement struct {
+		Element
+	}
+	el := foreignElement{
+		Element: NewBuilder("x:n1").
+			WithAttribute(xmlNamespace+":x", "ns1").
+			WithText("some text").
+			WithChild(NewBuilder("n2").Build()).
+			Build(),
+	}
+
+	// when
+	local := LocalName(el)
+	ns := NamespaceURI(el)
+	nodes := Nodes(el)
+	cp := DeepCopy(el)
+
+	// then
+	require.Equal(t, "n1", local)
+	require.Equal(t, "ns1", ns)
+	require.Len(t, nodes, 2)
+	require.Equal(t, el.String(), cp.String())
+	require.Equal(t, Digest(el.Element, sha256.New()), Digest(el, sha256.New()))
+}
+
 func TestElement_MarshalBinary(t *testing.T) {
 	el := NewBuilderFromElement(nil).
 		WithName("n1").
@@ -15,12 +15,15 @@
 package stanzaerror
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/jackal-xmpp/stravaganza"
+	"github.com/jackal-xmpp/stravaganza/internal/mapsutil"
 )
 
-const xmppStanzaNamespace = "urn:ietf:params:xml:ns:xmpp-stanzas"
+// Namespace is the stanza error conditions namespace.
+const Namespace = "urn:ietf:params:xml:ns:xmpp-stanzas"
 
 // Type is the stanza error type.
 type Type uint8
@@ -225,11 +228,19 @@ var reason2Str = map[Reason]string{
 	UnexpectedRequest:     "unexpected-request",
 }
 
+var str2Type = mapsutil.Reverse(type2Str)
+
+var str2Reason = mapsutil.Reverse(reason2Str)
+
 // Error represents a stanza "error" element.
 type Error struct {
 	// Reason is the stanza error reason type.
 	Reason Reason
 
+	// By is the entity that returned the error.
+	// Only set when known to differ from the stanza 'from' attribute.
+	By string
+
 	// SentElement is the original XMPP element that originated the stanza error.
 	SentElement stravaganza.Element
 
@@ -242,6 +253,8 @@ type Error struct {
 
 	// ApplicationElement defines the application specific condition element.
 	ApplicationElement stravaganza.Element
+
+	typ *Type
 }
 
 // Error method satisfies error interface.
@@ -249,6 +262,15 @@ func (se *Error) Error() string {
 	return se.Reason.String()
 }
 
+// Type returns the stanza error type.
+// Unless parsed from an element, the type associated to the error reason is returned.
+func (se *Error) Type() Type {
+	if se.typ != nil {
+		return *se.typ
+	}
+	return se.Reason.Type()
+}
+
 // Element returns se XMPP generic element.
 func (se *Error) Element() stravaganza.Element {
 	return stravaganza.NewBuilderFromElement(se.SentElement).
@@ -273,15 +295,18 @@ func (se *Error) Stanza(validateJIDs bool) (stravaganza.Stanza, error) {
 func (se *Error) errSubElement() stravaganza.Element {
 	b := stravaganza.NewBuilder("error")
 	b.WithAttribute("code", strconv.Itoa(se.Reason.Code()))
-	b.WithAttribute("type", se.Reason.Type().String())
+	b.WithAttribute("type", se.Type().String())
+	if len(se.By) > 0 {
+		b.WithAttribute("by", se.By)
+	}
 	b.WithChild(
 		stravaganza.NewBuilder(se.Reason.String()).
-			WithAttribute(stravaganza.Namespace, xmppStanzaNamespace).
+			WithAttribute(stravaganza.Namespace, Namespace).
 			Build(),
 	)
 	if len(se.Text) > 0 {
 		tb := stravaganza.NewBuilder("text").
-			WithAttribute(stravaganza.Namespace, xmppStanzaNamespace)
+			WithAttribute(stravaganza.Namespace, Namespace)
 		if len(se.Lang) > 0 {
 			tb.WithAttribute(stravaganza.Language, se.Lang)
 		} else {
@@ -301,3 +326,56 @@ func (se *Error) errSubElement() stravaganza.Element {
 func E(reason Reason, sentElement stravaganza.Element) *Error {
 	return &Error{Reason: reason, SentElement: sentElement}
 }
+
+// FromElement parses a stanza error from an <error/> element.
+// Unknown defined conditions are reported as UndefinedCondition errors.
+// Returned error SentElement is always nil, given that it can't be recovered.
+func FromElement(el stravaganza.Element) (*Error, error) {
+	if el == nil || stravaganza.LocalName(el) != "error" {
+		return nil, errors.New("stanzaerror: not an error element")
+	}
+	se := &Error{
+		Reason: UndefinedCondition,
+		By:     el.Attribute("by"),
+	}
+	if tp, ok := str2Type[el.Attribute(stravaganza.Type)]; ok {
+		se.typ = &tp
+	}
+	var hasCondition bool
+	for _, child := range el.AllChildren() {
+		switch {
+		case stravaganza.NamespaceURI(child) != Namespace:
+			if se.ApplicationElement == nil {
+				se.ApplicationElement = child
+			}
+		case stravaganza.LocalName(child) == "text":
+			se.Text = child.Text()
+			se.Lang = child.Attribute(stravaganza.Language)
+		case !hasCondition:
+			if reason, ok := ParseReason(stravaganza.LocalName(child)); ok {
+				se.Reason = reason
+			}
+			hasCondition = true
+		}
+	}
+	return se, nil
+}
+
+// FromStanza parses the stanza error contained into an 'error' type stanza.
+func FromStanza(stanza stravaganza.Element) (*Error, error) {
+	if stanza == nil || stanza.Attribute(stravaganza.Type) != stravaganza.ErrorType {
+		return nil, errors.New("stanzaerror: not an error stanza")
+	}
+	errEl := stanza.Child("error")
+	if errEl == nil {
+		return nil, errors.New("stanzaerror: missing error element")
+	}
+	return FromElement(errEl)
+}
+
+// ParseReason returns the Reason whose string representation is s, reporting whether it was found.
+// Useful to parse defined conditions carried outside an <error/> element.
+func ParseReason(s string) (Reason, bool) {
+	r, ok := str2Reason[s]
+	return r, ok
+}
@@ -15,6 +15,8 @@
 package stanzaerror
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/jackal-xmpp/stravaganza"
@@ -172,6 +174,92 @@ func TestStanzaError_Stanza(t *testing.T) {
 	require.Equal(t, expectedOutput, seStanza.String())
 }
 
+func TestStanzaError_By(t *testing.T) {
+	// given
+	se := E(RemoteServerNotFound, testMessageStanza())
+	se.By = "jackal.im"
+
+	// when
+	errEl := se.Element().Child("error")
+
+	// then
+	require.Equal(t, "jackal.im", errEl.Attribute("by"))
+}
+
+func TestStanzaError_FromStanza(t *testing.T) {
+	// given
+	se := E(InternalServerError, testMessageStanza())
+	se.By = "jackal.im"
+	se.Lang = "es"
+	se.Text = "Error interno de servidor"
+	se.ApplicationElement = stravaganza.NewBuilder("app-specific").
+		WithAttribute(stravaganza.Namespace, "app-ns").
+		Build()
+
+	seStanza, _ := se.Stanza(true)
+
+	// when
+	parsed, err := FromStanza(seStanza)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, InternalServerError, parsed.Reason)
+	require.Equal(t, Wait, parsed.Type())
+	require.Equal(t, "jackal.im", parsed.By)
+	require.Equal(t, "es", parsed.Lang)
+	require.Equal(t, "Error interno de servidor", parsed.Text)
+	require.NotNil(t, parsed.ApplicationElement)
+	require.Equal(t, "app-specific", parsed.ApplicationElement.Name())
+	require.Nil(t, parsed.SentElement)
+
+	var target *Error
+	require.True(t, errors.As(fmt.Errorf("iq failed: %w", parsed), &target))
+	require.Equal(t, InternalServerError, target.Reason)
+}
+
+func TestStanzaError_FromElementUnknownCondition(t *testing.T) {
+	// given
+	errEl := stravaganza.NewBuilder("error").
+		WithAttribute(stravaganza.Type, "modify").
+		WithChild(
+			stravaganza.NewBuilder("unknown-condition").
+				WithAttribute(stravaganza.Namespace, "urn:ietf:params:xml:ns:xmpp-stanzas").
+				Build(),
+		).
+		Build()
+
+	// when
+	se, err := FromElement(errEl)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, UndefinedCondition, se.Reason)
+	require.Equal(t, Modify, se.Type())
+	require.Nil(t, se.ApplicationElement)
+}
+
+func TestStanzaError_ParseReason(t *testing.T) {
+	r, ok := ParseReason("item-not-found")
+	require.True(t, ok)
+	require.Equal(t, ItemNotFound, r)
+
+	_, ok = ParseReason("unknown-condition")
+	require.False(t, ok)
+}
+
+func TestStanzaError_FromStanzaNotError(t *testing.T) {
+	// given
+	msg := testMessageStanza()
+
+	// when
+	_, err1 := FromStanza(msg)
+	_, err2 := FromElement(msg)
+
+	// then
+	require.NotNil(t, err1)
+	require.NotNil(t, err2)
+}
+
 func testMessageStanza() *stravaganza.Message {
 	b := stravaganza.NewMessageBuilder()
 	b.WithValidateJIDs(true)
@@ -15,12 +15,17 @@
 package streamerror
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/jackal-xmpp/stravaganza"
+	"github.com/jackal-xmpp/stravaganza/internal/mapsutil"
 )
 
-const xmppStanzaNamespace = "urn:ietf:params:xml:ns:xmpp-stanzas"
+const (
+	streamNamespace      = "http://etherx.jabber.org/streams"
+	xmppStreamsNamespace = "urn:ietf:params:xml:ns:xmpp-streams"
+)
 
 // Reason is the class of the stream error.
 type Reason uint8
@@ -41,6 +46,16 @@ const (
 	SystemShutdown                       // System shutdown.
 	UndefinedCondition                   // Undefined condition.
 	InternalServerError                  // Internal server error.
+	BadFormat                            // Bad format.
+	BadNamespacePrefix                   // Bad namespace prefix.
+	HostGone                             // Host gone.
+	ImproperAddressing                   // Improper addressing.
+	NotWellFormed                        // Not well-formed.
+	Reset                                // Reset.
+	RestrictedXML                        // Restricted XML.
+	SeeOtherHost                         // See other host.
+	UnsupportedEncoding                  // Unsupported encoding.
+	UnsupportedFeature                   // Unsupported feature.
 )
 
 var reason2Str = map[Reason]string{
@@ -59,8 +74,20 @@ var reason2Str = map[Reason]string{
 	SystemShutdown:         "system-shutdown",
 	UndefinedCondition:     "undefined-condition",
 	InternalServerError:    "internal-server-error",
+	BadFormat:              "bad-format",
+	BadNamespacePrefix:     "bad-namespace-prefix",
+	HostGone:               "host-gone",
+	ImproperAddressing:     "improper-addressing",
+	NotWellFormed:          "not-well-formed",
+	Reset:                  "reset",
+	RestrictedXML:          "restricted-xml",
+	SeeOtherHost:           "see-other-host",
+	UnsupportedEncoding:    "unsupported-encoding",
+	UnsupportedFeature:     "unsupported-feature",
 }
 
+var str2Reason = mapsutil.Reverse(reason2Str)
+
 // String returns string error reason representation.
 func (r Reason) String() string { return reason2Str[r] }
 
@@ -73,12 +100,16 @@ type Error struct {
 	Err error
 
 	// Lang is the error text lang code.
-	// If none assigned 'en' would be considered by default.
+	// If none assigned, text language is left unspecified.
 	Lang string
 
 	// Text is the error descriptive text.
 	Text string
 
+	// Host is the alternate host to which the initiating entity should reconnect.
+	// Only applies to SeeOtherHost stream errors.
+	Host string
+
 	// ApplicationElement defines the application specific condition element.
 	ApplicationElement stravaganza.Element
 }
@@ -86,11 +117,24 @@ type Error struct {
 // Element returns stream error XML node.
 func (se *Error) Element() stravaganza.Element {
 	b := stravaganza.NewBuilder("stream:error")
-	b.WithChild(
-		stravaganza.NewBuilder(se.Reason.String()).
-			WithAttribute(stravaganza.Namespace, xmppStanzaNamespace).
-			Build(),
-	)
+
+	cb := stravaganza.NewBuilder(se.Reason.String()).
+		WithAttribute(stravaganza.Namespace, xmppStreamsNamespace)
+	if se.Reason == SeeOtherHost {
+		cb.WithText(se.Host)
+	}
+	b.WithChild(cb.Build())
+
+	if len(se.Text) > 0 {
+		tb := stravaganza.NewBuilder("text").
+			WithAttribute(stravaganza.Namespace, xmppStreamsNamespace)
+		if len(se.Lang) > 0 {
+			tb.WithAttribute(stravaganza.Language, se.Lang)
+		}
+		tb.WithText(se.Text)
+
+		b.WithChild(tb.Build())
+	}
 	if se.ApplicationElement != nil {
 		b.WithChild(se.ApplicationElement)
 	}
@@ -109,3 +153,40 @@ func (se *Error) Error() string {
 func E(reason Reason) *Error {
 	return &Error{Reason: reason}
 }
+
+// SeeOtherHostE builds a see-other-host stream error value pointing to host.
+// host can be a domain name, an IPv4 or IPv6 address (enclosed in brackets), optionally followed by a port.
+func SeeOtherHostE(host string) *Error {
+	return &Error{Reason: SeeOtherHost, Host: host}
+}
+
+// FromElement parses a stream error from a <stream:error/> element.
+// Unknown defined conditions are reported as UndefinedCondition errors.
+func FromElement(el stravaganza.Element) (*Error, error) {
+	if el == nil || (el.Name() != "stream:error" && (stravaganza.LocalName(el) != "error" || stravaganza.NamespaceURI(el) != streamNamespace)) {
+		return nil, errors.New("streamerror: not a stream error element")
+	}
+	se := &Error{Reason: UndefinedCondition}
+
+	var hasCondition bool
+	for _, child := range el.AllChildren() {
+		switch {
+		case stravaganza.NamespaceURI(child) != xmppStreamsNamespace:
+			if se.ApplicationElement == nil {
+				se.ApplicationElement = child
+			}
+		case stravaganza.LocalName(child) == "text":
+			se.Text = child.Text()
+			se.Lang = child.Attribute(stravaganza.Language)
+		case !hasCondition:
+			if reason, ok := str2Reason[stravaganza.LocalName(child)]; ok {
+				se.Reason = reason
+			}
+			if se.Reason == SeeOtherHost {
+				se.Host = child.Text()
+			}
+			hasCondition = true
+		}
+	}
+	return se, nil
+}
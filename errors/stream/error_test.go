@@ -15,6 +15,8 @@
 package streamerror
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/jackal-xmpp/stravaganza"
@@ -50,6 +52,16 @@ func TestStreamError_Name(t *testing.T) {
 	seSystemShutdown := E(SystemShutdown)
 	seUndefinedCondition := E(UndefinedCondition)
 	seInternalServerError := E(InternalServerError)
+	seBadFormat := E(BadFormat)
+	seBadNamespacePrefix := E(BadNamespacePrefix)
+	seHostGone := E(HostGone)
+	seImproperAddressing := E(ImproperAddressing)
+	seNotWellFormed := E(NotWellFormed)
+	seReset := E(Reset)
+	seRestrictedXML := E(RestrictedXML)
+	seSeeOtherHost := E(SeeOtherHost)
+	seUnsupportedEncoding := E(UnsupportedEncoding)
+	seUnsupportedFeature := E(UnsupportedFeature)
 
 	// then
 	require.Equal(t, "invalid-xml", seInvalidXML.Error())
@@ -67,6 +79,16 @@ func TestStreamError_Name(t *testing.T) {
 	require.Equal(t, "system-shutdown", seSystemShutdown.Error())
 	require.Equal(t, "undefined-condition", seUndefinedCondition.Error())
 	require.Equal(t, "internal-server-error", seInternalServerError.Error())
+	require.Equal(t, "bad-format", seBadFormat.Error())
+	require.Equal(t, "bad-namespace-prefix", seBadNamespacePrefix.Error())
+	require.Equal(t, "host-gone", seHostGone.Error())
+	require.Equal(t, "improper-addressing", seImproperAddressing.Error())
+	require.Equal(t, "not-well-formed", seNotWellFormed.Error())
+	require.Equal(t, "reset", seReset.Error())
+	require.Equal(t, "restricted-xml", seRestrictedXML.Error())
+	require.Equal(t, "see-other-host", seSeeOtherHost.Error())
+	require.Equal(t, "unsupported-encoding", seUnsupportedEncoding.Error())
+	require.Equal(t, "unsupported-feature", seUnsupportedFeature.Error())
 }
 
 func TestStreamError_Element(t *testing.T) {
@@ -88,4 +110,97 @@ func TestStreamError_Element(t *testing.T) {
 	require.Equal(t, "stream:error", el.Name())
 	require.Equal(t, "policy-violation", errEl.Name())
 	require.Equal(t, "connection-limit-reached", appEl.Name())
+
+	require.Equal(t, `<stream:error>`+
+		`<policy-violation xmlns='urn:ietf:params:xml:ns:xmpp-streams'/>`+
+		`<text xmlns='urn:ietf:params:xml:ns:xmpp-streams' xml:lang='es'>Límite de conexiones alcanzado</text>`+
+		`<connection-limit-reached/>`+
+		`</stream:error>`, el.String())
+}
+
+func TestStreamError_SeeOtherHostElement(t *testing.T) {
+	// given
+	se := SeeOtherHostE("[2001:41D0:1:A49b::1]:9222")
+	se.Text = "Moved"
+
+	// when
+	el := se.Element()
+
+	// then
+	require.Equal(t, `<stream:error>`+
+		`<see-other-host xmlns='urn:ietf:params:xml:ns:xmpp-streams'>[2001:41D0:1:A49b::1]:9222</see-other-host>`+
+		`<text xmlns='urn:ietf:params:xml:ns:xmpp-streams'>Moved</text>`+
+		`</stream:error>`, el.String())
+}
+
+func TestStreamError_FromElement(t *testing.T) {
+	// given
+	se := E(PolicyViolation)
+	se.Lang = "es"
+	se.Text = "Demasiadas conexiones"
+	se.ApplicationElement = stravaganza.NewBuilder("too-many-connections").
+		WithAttribute(stravaganza.Namespace, "app-ns").
+		Build()
+
+	// when
+	parsed, err := FromElement(se.Element())
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, PolicyViolation, parsed.Reason)
+	require.Equal(t, "es", parsed.Lang)
+	require.Equal(t, "Demasiadas conexiones", parsed.Text)
+	require.NotNil(t, parsed.ApplicationElement)
+	require.Equal(t, "too-many-connections", parsed.ApplicationElement.Name())
+
+	var target *Error
+	require.True(t, errors.As(fmt.Errorf("stream closed: %w", parsed), &target))
+	require.Equal(t, PolicyViolation, target.Reason)
+}
+
+func TestStreamError_FromElementNoLang(t *testing.T) {
+	// given
+	se := E(Conflict)
+	se.Text = "Replaced by new connection"
+
+	// when
+	parsed, err := FromElement(se.Element())
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, "", parsed.Lang)
+	require.Equal(t, "Replaced by new connection", parsed.Text)
+}
+
+func TestStreamError_FromElementSeeOtherHost(t *testing.T) {
+	// given
+	se := SeeOtherHostE("[2001:41D0:1:A49b::1]:9222")
+
+	// when
+	parsed, err := FromElement(se.Element())
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, SeeOtherHost, parsed.Reason)
+	require.Equal(t, "[2001:41D0:1:A49b::1]:9222", parsed.Host)
+}
+
+func TestStreamError_FromElementUnknownCondition(t *testing.T) {
+	// given
+	el := stravaganza.NewBuilder("stream:error").
+		WithChild(
+			stravaganza.NewBuilder("unknown-condition").
+				WithAttribute(stravaganza.Namespace, "urn:ietf:params:xml:ns:xmpp-streams").
+				Build(),
+		).
+		Build()
+
+	// when
+	parsed, err := FromElement(el)
+	_, notStreamErr := FromElement(stravaganza.NewBuilder("error").Build())
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, UndefinedCondition, parsed.Reason)
+	require.NotNil(t, notStreamErr)
 }
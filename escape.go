@@ -50,11 +50,11 @@ var (
 // escapeText writes to w the properly escaped XML equivalent
 // of the plain text data s. If escapeNewline is true, newline
 // characters will be escaped.
-func escapeText(w io.Writer, s []byte, escapeNewline bool) error {
+func escapeText(w io.Writer, s string, escapeNewline bool) error {
 	var esc []byte
 	last := 0
 	for i := 0; i < len(s); {
-		r, width := utf8.DecodeRune(s[i:])
+		r, width := utf8.DecodeRuneInString(s[i:])
 		i += width
 		switch r {
 		case '"':
@@ -83,7 +83,7 @@ func escapeText(w io.Writer, s []byte, escapeNewline bool) error {
 			}
 			continue
 		}
-		if _, err := w.Write(s[last : i-width]); err != nil {
+		if _, err := io.WriteString(w, s[last:i-width]); err != nil {
 			return err
 		}
 		if _, err := w.Write(esc); err != nil {
@@ -91,12 +91,20 @@ func escapeText(w io.Writer, s []byte, escapeNewline bool) error {
 		}
 		last = i
 	}
-	if _, err := w.Write(s[last:]); err != nil {
+	if _, err := io.WriteString(w, s[last:]); err != nil {
 		return err
 	}
 	return nil
 }
 
+// escapeAttrValue writes to w the properly escaped XML equivalent
+// of the attribute value s. Along with markup characters, both quote
+// characters, tabs and newlines are escaped so that the value is not
+// altered by attribute-value normalization.
+func escapeAttrValue(w io.Writer, s string) error {
+	return escapeText(w, s, true)
+}
+
 // Decide whether the given rune is in the XML Character Range, per
 // the Char production of http://www.xml.com/axml/testaxml.htm,
 // Section 2.2 Characters.
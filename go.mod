@@ -3,8 +3,6 @@ module github.com/jackal-xmpp/stravaganza
 go 1.19
 
 require (
-	github.com/golang/protobuf v1.5.2
-	github.com/klauspost/cpuid/v2 v2.2.3
 	github.com/stretchr/testify v1.7.1
 	golang.org/x/net v0.0.0-20220526153639-5463443f8c37
 	golang.org/x/text v0.3.7
@@ -14,6 +12,5 @@ require (
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/sys v0.0.0-20220704084225-05e143d24a9e // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -28,6 +28,20 @@ type Attribute struct {
 	Value string
 }
 
+// Node represents an XML content node, either a text node or a sub element.
+type Node struct {
+	// Element is the sub element value, nil in case of a text node.
+	Element Element
+
+	// Text is the text node value.
+	Text string
+}
+
+// IsText returns true if n is a text node.
+func (n Node) IsText() bool {
+	return n.Element == nil
+}
+
 // AttributeReader defines an XML attributes read-only interface.
 type AttributeReader interface {
 	// AllAttributes returns a list of all node attributes.
@@ -56,6 +70,7 @@ type ElementReader interface {
 	Children(name string) []Element
 
 	// ChildNamespace returns first element identified by name and namespace.
+	// An element matches either by its name and 'xmlns' attribute or by its local name and namespace URI.
 	// Returns nil if no element is found.
 	ChildNamespace(name, ns string) Element
 
@@ -70,7 +85,19 @@ type XMLSerializer interface {
 	ToXML(w io.Writer, includeClosing bool) error
 }
 
+// CanonicalXMLSerializer represents element canonical XML serializer interface.
+type CanonicalXMLSerializer interface {
+	// ToCanonicalXML serializes element following Exclusive XML Canonicalization rules.
+	// Attributes are sorted, only visibly utilized namespaces are declared,
+	// values are double quoted and empty elements are written with an explicit end tag.
+	ToCanonicalXML(w io.Writer) error
+}
+
 // Element represents a generic XML node element.
+//
+// Elements built by this package also satisfy CanonicalXMLSerializer, json.Marshaler, json.Unmarshaler
+// and fmt.Formatter interfaces. Namespace, mixed content, digest and deep copy capabilities are available
+// for any Element value through LocalName, NamespaceURI, Nodes, Digest and DeepCopy functions.
 type Element interface {
 	AttributeReader
 	ElementReader
@@ -84,9 +111,12 @@ type Element interface {
 	Name() string
 
 	// Text returns XML node text value.
+	// In case of mixed content all text nodes are concatenated.
 	Text() string
 
 	// Proto returns element protobuf message.
+	// Returned message must be considered read-only, as it might be shared across several elements.
+	// For the same reason, neither the message nor any of its sub messages must be returned to a vtproto pool.
 	Proto() *PBElement
 }
 
@@ -0,0 +1,26 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mapsutil provides map helpers shared across stravaganza packages.
+package mapsutil
+
+// Reverse returns a map indexed by m values, mapping each one of them to its key.
+// Values are expected to be unique, otherwise only one of the keys is retained.
+func Reverse[K comparable](m map[K]string) map[string]K {
+	rm := make(map[string]K, len(m))
+	for k, v := range m {
+		rm[v] = k
+	}
+	return rm
+}
@@ -0,0 +1,153 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iqmux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/jackal-xmpp/stravaganza"
+	stanzaerror "github.com/jackal-xmpp/stravaganza/errors/stanza"
+)
+
+// Handler defines the interface implemented by IQ request handlers.
+type Handler interface {
+	// HandleIQ processes an IQ request returning the stanza to be sent back.
+	// A nil stanza and nil error means the handler takes care of replying by itself.
+	// Any returned *stanzaerror.Error will be sent back as an error response,
+	// while any other error will be reported as an internal-server-error.
+	HandleIQ(ctx context.Context, iq *stravaganza.IQ) (stravaganza.Stanza, error)
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as IQ handlers.
+type HandlerFunc func(ctx context.Context, iq *stravaganza.IQ) (stravaganza.Stanza, error)
+
+// HandleIQ calls f(ctx, iq).
+func (f HandlerFunc) HandleIQ(ctx context.Context, iq *stravaganza.IQ) (stravaganza.Stanza, error) {
+	return f(ctx, iq)
+}
+
+type payload struct {
+	name, namespace string
+}
+
+type route struct {
+	payload
+	iqType string
+}
+
+// Mux dispatches IQ requests to the handler registered for their payload name, namespace and type.
+// It's safe to use from multiple goroutines.
+type Mux struct {
+	mu       sync.RWMutex
+	handlers map[route]Handler
+	payloads map[payload]int
+}
+
+// New returns a new initialized IQ multiplexer.
+func New() *Mux {
+	return &Mux{
+		handlers: make(map[route]Handler),
+		payloads: make(map[payload]int),
+	}
+}
+
+// Handle registers h to handle iqType requests whose payload element matches name and namespace.
+// Handle panics if iqType is neither 'get' nor 'set', or if a handler was already registered for the same route.
+func (m *Mux) Handle(name, namespace, iqType string, h Handler) {
+	if iqType != stravaganza.GetType && iqType != stravaganza.SetType {
+		panic(fmt.Sprintf("iqmux: invalid iq type: %s", iqType))
+	}
+	if h == nil {
+		panic("iqmux: nil handler")
+	}
+	r := route{payload: payload{name: name, namespace: namespace}, iqType: iqType}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.handlers[r]; ok {
+		panic(fmt.Sprintf("iqmux: multiple registrations for {%s}%s (%s)", namespace, name, iqType))
+	}
+	m.handlers[r] = h
+	m.payloads[r.payload]++
+}
+
+// HandleFunc registers f to handle iqType requests whose payload element matches name and namespace.
+func (m *Mux) HandleFunc(name, namespace, iqType string, f func(ctx context.Context, iq *stravaganza.IQ) (stravaganza.Stanza, error)) {
+	m.Handle(name, namespace, iqType, HandlerFunc(f))
+}
+
+// HandleIQ dispatches iq to its registered handler.
+// Unhandled requests are answered with a service-unavailable error, or with feature-not-implemented
+// in case the payload is handled for a different request type.
+// 'result' and 'error' type IQs are ignored.
+func (m *Mux) HandleIQ(ctx context.Context, iq *stravaganza.IQ) (stravaganza.Stanza, error) {
+	if !iq.IsGet() && !iq.IsSet() {
+		return nil, nil
+	}
+	children := iq.AllChildren()
+	if len(children) != 1 {
+		return stanzaerror.E(stanzaerror.BadRequest, iq).Stanza(false)
+	}
+	p := payload{name: stravaganza.LocalName(children[0]), namespace: stravaganza.NamespaceURI(children[0])}
+
+	m.mu.RLock()
+	h, ok := m.handlers[route{payload: p, iqType: iq.Type()}]
+	known := m.payloads[p] > 0
+	m.mu.RUnlock()
+
+	if !ok {
+		if known {
+			return stanzaerror.E(stanzaerror.FeatureNotImplemented, iq).Stanza(false)
+		}
+		return stanzaerror.E(stanzaerror.ServiceUnavailable, iq).Stanza(false)
+	}
+	resp, err := h.HandleIQ(ctx, iq)
+	if err == nil {
+		return resp, nil
+	}
+	var se *stanzaerror.Error
+	if !errors.As(err, &se) {
+		return stanzaerror.E(stanzaerror.InternalServerError, iq).Stanza(false)
+	}
+	if se.SentElement == nil {
+		cp := *se
+		cp.SentElement = iq
+		se = &cp
+	}
+	return se.Stanza(false)
+}
+
+// Features returns the sorted list of namespaces handled by the multiplexer,
+// suitable for service discovery.
+func (m *Mux) Features() []string {
+	m.mu.RLock()
+	set := make(map[string]struct{}, len(m.payloads))
+	for p := range m.payloads {
+		set[p.namespace] = struct{}{}
+	}
+	m.mu.RUnlock()
+
+	features := make([]string, 0, len(set))
+	for ns := range set {
+		features = append(features, ns)
+	}
+	sort.Strings(features)
+	return features
+}
@@ -0,0 +1,153 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iqmux
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza"
+	stanzaerror "github.com/jackal-xmpp/stravaganza/errors/stanza"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	versionNamespace = "jabber:iq:version"
+	rosterNamespace  = "jabber:iq:roster"
+)
+
+func TestMux_Dispatch(t *testing.T) {
+	// given
+	m := New()
+	m.HandleFunc("query", versionNamespace, stravaganza.GetType, func(_ context.Context, iq *stravaganza.IQ) (stravaganza.Stanza, error) {
+		return iq.ResultBuilder().
+			WithChild(
+				stravaganza.NewBuilder("query").
+					WithAttribute(stravaganza.Namespace, versionNamespace).
+					WithChild(stravaganza.NewBuilder("name").WithText("jackal").Build()).
+					Build(),
+			).
+			BuildIQ()
+	})
+
+	// when
+	resp, err := m.HandleIQ(context.Background(), testIQ(t, stravaganza.GetType, "query", versionNamespace))
+
+	// then
+	require.Nil(t, err)
+	require.NotNil(t, resp)
+	require.Equal(t, stravaganza.ResultType, resp.Type())
+	require.Equal(t, "jackal", resp.Child("query").Child("name").Text())
+}
+
+func TestMux_Unhandled(t *testing.T) {
+	// given
+	m := New()
+	m.HandleFunc("query", rosterNamespace, stravaganza.GetType, func(_ context.Context, iq *stravaganza.IQ) (stravaganza.Stanza, error) {
+		return iq.ResultBuilder().BuildIQ()
+	})
+
+	// when
+	resp1, err1 := m.HandleIQ(context.Background(), testIQ(t, stravaganza.SetType, "query", rosterNamespace))
+	resp2, err2 := m.HandleIQ(context.Background(), testIQ(t, stravaganza.GetType, "query", versionNamespace))
+
+	// then
+	require.Nil(t, err1)
+	require.Nil(t, err2)
+
+	se1, _ := stanzaerror.FromStanza(resp1)
+	require.Equal(t, stanzaerror.FeatureNotImplemented, se1.Reason)
+	require.Equal(t, "noelia@jackal.im/yard", resp1.Attribute(stravaganza.To))
+
+	se2, _ := stanzaerror.FromStanza(resp2)
+	require.Equal(t, stanzaerror.ServiceUnavailable, se2.Reason)
+}
+
+func TestMux_HandlerErrors(t *testing.T) {
+	// given
+	m := New()
+	m.HandleFunc("query", rosterNamespace, stravaganza.SetType, func(_ context.Context, _ *stravaganza.IQ) (stravaganza.Stanza, error) {
+		return nil, &stanzaerror.Error{Reason: stanzaerror.NotAllowed}
+	})
+	m.HandleFunc("query", rosterNamespace, stravaganza.GetType, func(_ context.Context, _ *stravaganza.IQ) (stravaganza.Stanza, error) {
+		return nil, errors.New("storage failure")
+	})
+
+	// when
+	resp1, _ := m.HandleIQ(context.Background(), testIQ(t, stravaganza.SetType, "query", rosterNamespace))
+	resp2, _ := m.HandleIQ(context.Background(), testIQ(t, stravaganza.GetType, "query", rosterNamespace))
+
+	// then
+	se1, _ := stanzaerror.FromStanza(resp1)
+	require.Equal(t, stanzaerror.NotAllowed, se1.Reason)
+
+	se2, _ := stanzaerror.FromStanza(resp2)
+	require.Equal(t, stanzaerror.InternalServerError, se2.Reason)
+}
+
+func TestMux_IgnoresResponses(t *testing.T) {
+	// given
+	m := New()
+	iq, _ := testIQ(t, stravaganza.GetType, "query", versionNamespace).ResultBuilder().BuildIQ()
+
+	// when
+	resp, err := m.HandleIQ(context.Background(), iq)
+
+	// then
+	require.Nil(t, resp)
+	require.Nil(t, err)
+}
+
+func TestMux_Features(t *testing.T) {
+	// given
+	m := New()
+	h := HandlerFunc(func(_ context.Context, _ *stravaganza.IQ) (stravaganza.Stanza, error) { return nil, nil })
+	m.Handle("query", versionNamespace, stravaganza.GetType, h)
+	m.Handle("query", rosterNamespace, stravaganza.GetType, h)
+	m.Handle("query", rosterNamespace, stravaganza.SetType, h)
+
+	// when
+	features := m.Features()
+
+	// then
+	require.Equal(t, []string{rosterNamespace, versionNamespace}, features)
+}
+
+func TestMux_HandlePanics(t *testing.T) {
+	m := New()
+	h := HandlerFunc(func(_ context.Context, _ *stravaganza.IQ) (stravaganza.Stanza, error) { return nil, nil })
+	m.Handle("query", versionNamespace, stravaganza.GetType, h)
+
+	require.Panics(t, func() { m.Handle("query", versionNamespace, stravaganza.GetType, h) })
+	require.Panics(t, func() { m.Handle("query", versionNamespace, stravaganza.ResultType, h) })
+	require.Panics(t, func() { m.Handle("query", rosterNamespace, stravaganza.GetType, nil) })
+}
+
+func testIQ(t *testing.T, iqType, name, namespace string) *stravaganza.IQ {
+	iq, err := stravaganza.NewIQBuilder().
+		WithAttribute(stravaganza.ID, "1234").
+		WithAttribute(stravaganza.Type, iqType).
+		WithAttribute(stravaganza.From, "noelia@jackal.im/yard").
+		WithAttribute(stravaganza.To, "jackal.im").
+		WithChild(
+			stravaganza.NewBuilder(name).
+				WithAttribute(stravaganza.Namespace, namespace).
+				Build(),
+		).
+		BuildIQ()
+	require.Nil(t, err)
+	return iq
+}
@@ -0,0 +1,255 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iqtracker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackal-xmpp/stravaganza"
+	stanzaerror "github.com/jackal-xmpp/stravaganza/errors/stanza"
+	"github.com/jackal-xmpp/stravaganza/jid"
+)
+
+var (
+	// ErrDuplicateID will be returned by Register when a request with the same id and responder is already pending.
+	ErrDuplicateID = errors.New("iqtracker: duplicate request id")
+
+	// ErrNotRequest will be returned by Register when the iq is neither of 'get' nor 'set' type.
+	ErrNotRequest = errors.New("iqtracker: iq is not a request")
+
+	// ErrCanceled will be returned by Wait when the request has been canceled.
+	ErrCanceled = errors.New("iqtracker: request canceled")
+)
+
+// Option defines tracker option type.
+type Option func(*Tracker)
+
+// WithTimeout sets the maximum amount of time a request will be waiting for its response.
+func WithTimeout(timeout time.Duration) Option {
+	return func(t *Tracker) {
+		t.timeout = timeout
+	}
+}
+
+// Tracker correlates outgoing IQ requests with their responses.
+// It's safe to use from multiple goroutines.
+type Tracker struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	pending map[string][]*Pending
+}
+
+// New returns a new initialized IQ tracker.
+func New(opts ...Option) *Tracker {
+	t := &Tracker{
+		pending: make(map[string][]*Pending),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Pending represents an in-flight IQ request.
+type Pending struct {
+	tr        *Tracker
+	id        string
+	requester *jid.JID
+	responder string
+	canceled  chan struct{}
+	respCh    chan *stravaganza.IQ
+}
+
+// ID returns pending request identifier.
+func (p *Pending) ID() string {
+	return p.id
+}
+
+// Wait blocks until a response is delivered, the request is canceled or ctx is done.
+// In case an 'error' type response is received, the response is returned along with its parsed *stanzaerror.Error.
+func (p *Pending) Wait(ctx context.Context) (*stravaganza.IQ, error) {
+	if p.tr.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.tr.timeout)
+		defer cancel()
+	}
+	select {
+	case resp := <-p.respCh:
+		if !resp.IsError() {
+			return resp, nil
+		}
+		se, err := stanzaerror.FromStanza(resp)
+		if err != nil {
+			se = &stanzaerror.Error{Reason: stanzaerror.UndefinedCondition}
+		}
+		return resp, se
+
+	case <-p.canceled:
+		return nil, ErrCanceled
+
+	case <-ctx.Done():
+		p.Cancel()
+		return nil, ctx.Err()
+	}
+}
+
+// Cancel stops tracking the request.
+// Any response received after cancellation will be rejected.
+func (p *Pending) Cancel() {
+	if p.tr.remove(p) {
+		close(p.canceled)
+	}
+}
+
+// Register starts tracking iq request.
+func (t *Tracker) Register(iq *stravaganza.IQ) (*Pending, error) {
+	if !iq.IsGet() && !iq.IsSet() {
+		return nil, ErrNotRequest
+	}
+	p := &Pending{
+		tr:        t,
+		id:        iq.ID(),
+		requester: iq.FromJID(),
+		responder: normalizeJID(iq.ToJID().String()),
+		canceled:  make(chan struct{}),
+		respCh:    make(chan *stravaganza.IQ, 1),
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, other := range t.pending[p.id] {
+		if other.responder == p.responder {
+			return nil, ErrDuplicateID
+		}
+	}
+	t.pending[p.id] = append(t.pending[p.id], p)
+	return p, nil
+}
+
+// Deliver hands el over to its matching pending request.
+// Responses not matching any pending request, or coming from an unexpected responder, are rejected returning false.
+func (t *Tracker) Deliver(el stravaganza.Element) bool {
+	if el.Name() != stravaganza.IQName {
+		return false
+	}
+	switch el.Attribute(stravaganza.Type) {
+	case stravaganza.ResultType, stravaganza.ErrorType:
+		break
+	default:
+		return false
+	}
+	id := el.Attribute(stravaganza.ID)
+	from := el.Attribute(stravaganza.From)
+	if len(from) > 0 {
+		from = normalizeJID(from)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, p := range t.pending[id] {
+		if !p.accepts(from) {
+			continue
+		}
+		// fill in implicit addressing
+		b := stravaganza.NewBuilderFromElement(el)
+		if len(from) == 0 {
+			b.WithAttribute(stravaganza.From, p.responder)
+		}
+		if len(el.Attribute(stravaganza.To)) == 0 && p.requester != nil {
+			b.WithAttribute(stravaganza.To, p.requester.String())
+		}
+		resp, err := b.BuildIQ()
+		if err != nil {
+			return false
+		}
+		t.removeLocked(p)
+		p.respCh <- resp
+		return true
+	}
+	return false
+}
+
+// Request registers iq, sends it using send function and waits for its response.
+func (t *Tracker) Request(ctx context.Context, iq *stravaganza.IQ, send func(stravaganza.Stanza) error) (*stravaganza.IQ, error) {
+	p, err := t.Register(iq)
+	if err != nil {
+		return nil, err
+	}
+	if err := send(iq); err != nil {
+		p.Cancel()
+		return nil, err
+	}
+	return p.Wait(ctx)
+}
+
+// Len returns the number of pending requests.
+func (t *Tracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var n int
+	for _, ps := range t.pending {
+		n += len(ps)
+	}
+	return n
+}
+
+func (t *Tracker) remove(p *Pending) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.removeLocked(p)
+}
+
+func (t *Tracker) removeLocked(p *Pending) bool {
+	ps := t.pending[p.id]
+	for i, other := range ps {
+		if other != p {
+			continue
+		}
+		if len(ps) == 1 {
+			delete(t.pending, p.id)
+		} else {
+			t.pending[p.id] = append(ps[:i:i], ps[i+1:]...)
+		}
+		return true
+	}
+	return false
+}
+
+// accepts tells whether a response coming from 'from' address can answer the request,
+// as stated in RFC 6120 8.1.2.1.
+func (p *Pending) accepts(from string) bool {
+	if from == p.responder {
+		return true
+	}
+	if len(from) > 0 || p.requester == nil {
+		return false
+	}
+	// a response with no 'from' attribute is implicitly sent by the requester's
+	// account (bare JID) or by its server (domain).
+	return p.responder == p.requester.ToBareJID().String() || p.responder == p.requester.Domain()
+}
+
+func normalizeJID(s string) string {
+	j, err := jid.NewWithString(s, false)
+	if err != nil {
+		return s
+	}
+	return j.String()
+}
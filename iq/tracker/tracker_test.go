@@ -0,0 +1,262 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iqtracker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackal-xmpp/stravaganza"
+	stanzaerror "github.com/jackal-xmpp/stravaganza/errors/stanza"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker_Result(t *testing.T) {
+	// given
+	tr := New()
+	req := testRequest(t, "1", "ortuman@jackal.im/balcony", "noelia@jackal.im/yard")
+
+	p, err := tr.Register(req)
+	require.Nil(t, err)
+
+	// when
+	ok := tr.Deliver(req.ResultBuilder().Build())
+	resp, err := p.Wait(context.Background())
+
+	// then
+	require.True(t, ok)
+	require.Nil(t, err)
+	require.Equal(t, "1", resp.ID())
+	require.True(t, resp.IsResult())
+	require.Equal(t, 0, tr.Len())
+}
+
+func TestTracker_SpoofedResponse(t *testing.T) {
+	// given
+	tr := New()
+	req := testRequest(t, "1", "ortuman@jackal.im/balcony", "noelia@jackal.im/yard")
+
+	_, err := tr.Register(req)
+	require.Nil(t, err)
+
+	// when
+	spoofed := testResponse("1", stravaganza.ResultType, "mallory@jackal.im/evil", "ortuman@jackal.im/balcony")
+	noFrom := testResponse("1", stravaganza.ResultType, "", "ortuman@jackal.im/balcony")
+	unknownID := testResponse("2", stravaganza.ResultType, "noelia@jackal.im/yard", "ortuman@jackal.im/balcony")
+
+	// then
+	require.False(t, tr.Deliver(spoofed))
+	require.False(t, tr.Deliver(noFrom))
+	require.False(t, tr.Deliver(unknownID))
+	require.Equal(t, 1, tr.Len())
+}
+
+func TestTracker_ImplicitFrom(t *testing.T) {
+	var tcs = []struct {
+		name      string
+		to        string
+		from      string
+		delivered bool
+	}{
+		{name: "bare JID no from", to: "ortuman@jackal.im", from: "", delivered: true},
+		{name: "bare JID from bare JID", to: "ortuman@jackal.im", from: "ortuman@jackal.im", delivered: true},
+		{name: "server no from", to: "jackal.im", from: "", delivered: true},
+		{name: "server from server", to: "jackal.im", from: "jackal.im", delivered: true},
+		{name: "server from other server", to: "jackal.im", from: "evil.im", delivered: false},
+		{name: "remote server no from", to: "other.im", from: "", delivered: false},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			tr := New()
+			req := testRequest(t, "1", "ortuman@jackal.im/balcony", tc.to)
+
+			p, err := tr.Register(req)
+			require.Nil(t, err)
+
+			// when
+			ok := tr.Deliver(testResponse("1", stravaganza.ResultType, tc.from, ""))
+
+			// then
+			require.Equal(t, tc.delivered, ok)
+			if !tc.delivered {
+				return
+			}
+			resp, err := p.Wait(context.Background())
+			require.Nil(t, err)
+			require.Equal(t, "ortuman@jackal.im/balcony", resp.ToJID().String())
+		})
+	}
+}
+
+func TestTracker_ErrorResponse(t *testing.T) {
+	// given
+	tr := New()
+	req := testRequest(t, "1", "ortuman@jackal.im/balcony", "noelia@jackal.im/yard")
+
+	p, err := tr.Register(req)
+	require.Nil(t, err)
+
+	// when
+	errStanza, _ := stanzaerror.E(stanzaerror.ServiceUnavailable, req).Stanza(false)
+	ok := tr.Deliver(errStanza)
+
+	resp, err := p.Wait(context.Background())
+
+	// then
+	require.True(t, ok)
+	require.NotNil(t, resp)
+	require.True(t, resp.IsError())
+
+	var se *stanzaerror.Error
+	require.True(t, errors.As(err, &se))
+	require.Equal(t, stanzaerror.ServiceUnavailable, se.Reason)
+}
+
+func TestTracker_Timeout(t *testing.T) {
+	// given
+	tr := New(WithTimeout(time.Millisecond * 10))
+	req := testRequest(t, "1", "ortuman@jackal.im/balcony", "noelia@jackal.im/yard")
+
+	p, err := tr.Register(req)
+	require.Nil(t, err)
+
+	// when
+	_, err = p.Wait(context.Background())
+
+	// then
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Equal(t, 0, tr.Len())
+	require.False(t, tr.Deliver(req.ResultBuilder().Build()))
+}
+
+func TestTracker_Cancel(t *testing.T) {
+	// given
+	tr := New()
+	req := testRequest(t, "1", "ortuman@jackal.im/balcony", "noelia@jackal.im/yard")
+
+	p, err := tr.Register(req)
+	require.Nil(t, err)
+
+	// when
+	p.Cancel()
+	p.Cancel()
+
+	_, err = p.Wait(context.Background())
+
+	// then
+	require.Equal(t, ErrCanceled, err)
+	require.Equal(t, 0, tr.Len())
+}
+
+func TestTracker_Register(t *testing.T) {
+	// given
+	tr := New()
+	req1 := testRequest(t, "1", "ortuman@jackal.im/balcony", "noelia@jackal.im/yard")
+	req2 := testRequest(t, "1", "ortuman@jackal.im/balcony", "jackal.im")
+	res, _ := req1.ResultBuilder().BuildIQ()
+
+	// when
+	_, err1 := tr.Register(req1)
+	_, err2 := tr.Register(req1)
+	_, err3 := tr.Register(req2)
+	_, err4 := tr.Register(res)
+
+	// then
+	require.Nil(t, err1)
+	require.Equal(t, ErrDuplicateID, err2)
+	require.Nil(t, err3)
+	require.Equal(t, ErrNotRequest, err4)
+	require.Equal(t, 2, tr.Len())
+}
+
+func TestTracker_Request(t *testing.T) {
+	// given
+	tr := New()
+	req := testRequest(t, "1", "ortuman@jackal.im/balcony", "noelia@jackal.im/yard")
+
+	// when
+	resp, err := tr.Request(context.Background(), req, func(stanza stravaganza.Stanza) error {
+		go tr.Deliver(req.ResultBuilder().Build())
+		return nil
+	})
+
+	// then
+	require.Nil(t, err)
+	require.True(t, resp.IsResult())
+}
+
+func TestTracker_Concurrent(t *testing.T) {
+	tr := New()
+
+	// requests are built and responses checked from the test goroutine, as require must not be called from spawned ones
+	reqs := make([]*stravaganza.IQ, 32)
+	for i := range reqs {
+		reqs[i] = testRequest(t, strconv.Itoa(i), "ortuman@jackal.im/balcony", "noelia@jackal.im/yard")
+	}
+	resps := make([]*stravaganza.IQ, len(reqs))
+	errs := make([]error, len(reqs))
+
+	var wg sync.WaitGroup
+	for i := range reqs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			req := reqs[i]
+			resps[i], errs[i] = tr.Request(context.Background(), req, func(stanza stravaganza.Stanza) error {
+				go tr.Deliver(req.ResultBuilder().Build())
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, req := range reqs {
+		require.Nil(t, errs[i])
+		require.Equal(t, req.ID(), resps[i].ID())
+	}
+	require.Equal(t, 0, tr.Len())
+}
+
+func testRequest(t *testing.T, id, from, to string) *stravaganza.IQ {
+	iq, err := stravaganza.NewIQBuilder().
+		WithValidateJIDs(true).
+		WithAttribute(stravaganza.ID, id).
+		WithAttribute(stravaganza.Type, stravaganza.GetType).
+		WithAttribute(stravaganza.From, from).
+		WithAttribute(stravaganza.To, to).
+		WithChild(
+			stravaganza.NewBuilder("query").
+				WithAttribute(stravaganza.Namespace, "jabber:iq:version").
+				Build(),
+		).
+		BuildIQ()
+	require.Nil(t, err)
+	return iq
+}
+
+func testResponse(id, typ, from, to string) stravaganza.Element {
+	return stravaganza.NewIQBuilder().
+		WithAttribute(stravaganza.ID, id).
+		WithAttribute(stravaganza.Type, typ).
+		WithAttribute(stravaganza.From, from).
+		WithAttribute(stravaganza.To, to).
+		Build()
+}
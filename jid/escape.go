@@ -0,0 +1,117 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jid
+
+import (
+	"errors"
+	"strings"
+)
+
+var errNodeSpaceBoundary = errors.New("jid: unescaped node must not begin or end with a space")
+
+// XEP-0106 escaping transformations.
+var escapeSeqs = map[byte]string{
+	' ':  `\20`,
+	'"':  `\22`,
+	'&':  `\26`,
+	'\'': `\27`,
+	'/':  `\2f`,
+	':':  `\3a`,
+	'<':  `\3c`,
+	'>':  `\3e`,
+	'@':  `\40`,
+	'\\': `\5c`,
+}
+
+var unescapeSeqs = func() map[string]byte {
+	m := make(map[string]byte, len(escapeSeqs))
+	for c, seq := range escapeSeqs {
+		m[seq[1:]] = c
+	}
+	return m
+}()
+
+// NewWithUnescapedNode constructs a JID given an unescaped node, as entered by a user, a domain, and a resource.
+// The node is escaped as specified in XEP-0106 before constructing the JID.
+func NewWithUnescapedNode(node, domain, resource string, skipStringPrep bool) (*JID, error) {
+	if strings.HasPrefix(node, " ") || strings.HasSuffix(node, " ") {
+		return nil, errNodeSpaceBoundary
+	}
+	return New(EscapeNode(node), domain, resource, skipStringPrep)
+}
+
+// EscapeNode escapes node as specified in XEP-0106.
+// A backslash is only escaped when followed by a character sequence that would be interpreted as an escape sequence.
+func EscapeNode(node string) string {
+	var sb strings.Builder
+	sb.Grow(len(node))
+	for i := 0; i < len(node); i++ {
+		c := node[i]
+		seq, ok := escapeSeqs[c]
+		if !ok || (c == '\\' && !isEscapeSeq(node[i+1:])) {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteString(seq)
+	}
+	return sb.String()
+}
+
+// UnescapeNode reverts the XEP-0106 escaping transformation applied to node.
+func UnescapeNode(node string) string {
+	if !strings.Contains(node, `\`) {
+		return node
+	}
+	var sb strings.Builder
+	sb.Grow(len(node))
+	for i := 0; i < len(node); i++ {
+		if node[i] == '\\' && isEscapeSeq(node[i+1:]) {
+			sb.WriteByte(unescapeSeqs[strings.ToLower(node[i+1:i+3])])
+			i += 2
+			continue
+		}
+		sb.WriteByte(node[i])
+	}
+	return sb.String()
+}
+
+// UnescapedNode returns the XEP-0106 unescaped form of the node.
+func (j *JID) UnescapedNode() string {
+	return UnescapeNode(j.node)
+}
+
+// DisplayString returns a human readable representation of the JID, with its node unescaped.
+// Returned value is not a valid JID and must only be used for presentation purposes.
+func (j *JID) DisplayString() string {
+	var sb strings.Builder
+	if len(j.node) > 0 {
+		sb.WriteString(j.UnescapedNode())
+		sb.WriteString("@")
+	}
+	sb.WriteString(j.domain)
+	if len(j.resource) > 0 {
+		sb.WriteString("/")
+		sb.WriteString(j.resource)
+	}
+	return sb.String()
+}
+
+func isEscapeSeq(s string) bool {
+	if len(s) < 2 {
+		return false
+	}
+	_, ok := unescapeSeqs[strings.ToLower(s[:2])]
+	return ok
+}
@@ -0,0 +1,73 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jid_test
+
+import (
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza/jid"
+	"github.com/stretchr/testify/require"
+)
+
+// XEP-0106 section 5 examples
+var escapeVectors = []struct {
+	unescaped, escaped string
+}{
+	{`space cadet`, `space\20cadet`},
+	{`call me "ishmael"`, `call\20me\20\22ishmael\22`},
+	{`at&t guy`, `at\26t\20guy`},
+	{`d'artagnan`, `d\27artagnan`},
+	{`/.fanboy`, `\2f.fanboy`},
+	{`::foo::`, `\3a\3afoo\3a\3a`},
+	{`<foo>`, `\3cfoo\3e`},
+	{`user@host`, `user\40host`},
+	{`c:\net`, `c\3a\net`},
+	{`c:\\net`, `c\3a\\net`},
+	{`c:\cool stuff`, `c\3a\cool\20stuff`},
+	{`c:\5commas`, `c\3a\5c5commas`},
+	{`\20`, `\5c20`},
+	{`\5c`, `\5c5c`},
+	{`trailing\`, `trailing\`},
+}
+
+func TestEscapeNode(t *testing.T) {
+	for _, v := range escapeVectors {
+		require.Equal(t, v.escaped, jid.EscapeNode(v.unescaped), v.unescaped)
+		require.Equal(t, v.unescaped, jid.UnescapeNode(v.escaped), v.escaped)
+	}
+}
+
+func TestNewWithUnescapedNode(t *testing.T) {
+	j, err := jid.NewWithUnescapedNode("John Smith", "corp.example", "ldap", false)
+	require.Nil(t, err)
+	require.Equal(t, `john\20smith@corp.example/ldap`, j.String())
+	require.Equal(t, "john smith", j.UnescapedNode())
+	require.Equal(t, "john smith@corp.example/ldap", j.DisplayString())
+
+	j2, err := jid.NewWithUnescapedNode("d'artagnan", "corp.example", "", false)
+	require.Nil(t, err)
+	require.Equal(t, `d\27artagnan@corp.example`, j2.String())
+	require.Equal(t, "d'artagnan@corp.example", j2.DisplayString())
+
+	_, err = jid.NewWithUnescapedNode(" john", "corp.example", "", false)
+	require.NotNil(t, err)
+	_, err = jid.NewWithUnescapedNode("john ", "corp.example", "", false)
+	require.NotNil(t, err)
+
+	// escaped JIDs remain valid when parsed from their string representation
+	j3, err := jid.NewWithString(j.String(), false)
+	require.Nil(t, err)
+	require.Equal(t, j.String(), j3.String())
+}
@@ -0,0 +1,140 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jid
+
+// Key is a comparable value representation of a JID, suitable to be used as a map key.
+// It's made up of the canonical JID string along with its domain offsets, so that
+// deriving bare and domain keys doesn't allocate.
+// The zero value represents an empty JID.
+type Key struct {
+	s           string
+	domainStart uint16
+	domainEnd   uint16
+}
+
+// Key returns the comparable key representation of the JID.
+func (j *JID) Key() Key {
+	if len(j.node) == 0 && len(j.resource) == 0 {
+		return Key{s: j.domain, domainEnd: uint16(len(j.domain))}
+	}
+	return Key{
+		s:           j.String(),
+		domainStart: uint16(keyDomainStart(j.node)),
+		domainEnd:   uint16(keyDomainStart(j.node) + len(j.domain)),
+	}
+}
+
+// ParseKey parses a JID string into its key representation, applying stringprep.
+func ParseKey(s string) (Key, error) {
+	j, err := NewWithString(s, false)
+	if err != nil {
+		return Key{}, err
+	}
+	return j.Key(), nil
+}
+
+// JID returns the JID associated to k.
+func (k Key) JID() *JID {
+	return &JID{node: k.Node(), domain: k.domain(), resource: k.Resource()}
+}
+
+// String returns the JID string representation.
+func (k Key) String() string {
+	return k.s
+}
+
+// IsZero tells whether k represents an empty JID.
+func (k Key) IsZero() bool {
+	return len(k.s) == 0
+}
+
+// Node returns the node, or empty string if k does not contain node information.
+func (k Key) Node() string {
+	if k.domainStart == 0 {
+		return ""
+	}
+	return k.s[:k.domainStart-1]
+}
+
+// Resource returns the resource, or empty string if k does not contain resource information.
+func (k Key) Resource() string {
+	if int(k.domainEnd) == len(k.s) {
+		return ""
+	}
+	return k.s[k.domainEnd+1:]
+}
+
+// Bare returns the key with resource information removed.
+func (k Key) Bare() Key {
+	return Key{s: k.s[:k.domainEnd], domainStart: k.domainStart, domainEnd: k.domainEnd}
+}
+
+// Domain returns the key with node and resource information removed.
+func (k Key) Domain() Key {
+	return Key{s: k.domain(), domainEnd: k.domainEnd - k.domainStart}
+}
+
+// IsServer returns true if k represents a server JID.
+func (k Key) IsServer() bool {
+	return k.domainStart == 0
+}
+
+// IsBare returns true if k represents a bare JID.
+func (k Key) IsBare() bool {
+	return k.domainStart > 0 && int(k.domainEnd) == len(k.s)
+}
+
+// IsFull returns true if k represents a full JID.
+func (k Key) IsFull() bool {
+	return int(k.domainEnd) < len(k.s)
+}
+
+// Matches tells whether or not k2 matches k, following the same semantics as JID.Matches.
+func (k Key) Matches(k2 Key) bool {
+	switch {
+	case k.IsFull() && !k.IsServer():
+		return k == k2
+	case k.IsFull():
+		return k.MatchesWithOptions(k2, MatchesDomain|MatchesResource)
+	case k.IsBare():
+		return k == k2.Bare()
+	}
+	return k == k2.Domain()
+}
+
+// MatchesWithOptions tells whether two keys are equivalent based on matching options.
+func (k Key) MatchesWithOptions(k2 Key, options MatchingOptions) bool {
+	if (options&MatchesNode) > 0 && k.Node() != k2.Node() {
+		return false
+	}
+	if (options&MatchesDomain) > 0 && k.domain() != k2.domain() {
+		return false
+	}
+	if (options&MatchesResource) > 0 && k.Resource() != k2.Resource() {
+		return false
+	}
+	return true
+}
+
+func (k Key) domain() string {
+	return k.s[k.domainStart:k.domainEnd]
+}
+
+func keyDomainStart(node string) int {
+	if len(node) == 0 {
+		return 0
+	}
+	return len(node) + 1
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jid_test
+
+import (
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza/jid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey_Parts(t *testing.T) {
+	k, err := jid.ParseKey("ortuman@jackal.im/yard")
+	require.Nil(t, err)
+
+	require.Equal(t, "ortuman@jackal.im/yard", k.String())
+	require.Equal(t, "ortuman", k.Node())
+	require.Equal(t, "yard", k.Resource())
+	require.True(t, k.IsFull())
+	require.False(t, k.IsBare())
+	require.False(t, k.IsServer())
+
+	bare := k.Bare()
+	require.Equal(t, "ortuman@jackal.im", bare.String())
+	require.True(t, bare.IsBare())
+	require.Equal(t, "", bare.Resource())
+
+	domain := k.Domain()
+	require.Equal(t, "jackal.im", domain.String())
+	require.True(t, domain.IsServer())
+	require.Equal(t, "", domain.Node())
+
+	require.Equal(t, "ortuman@jackal.im/yard", k.JID().String())
+	require.True(t, jid.Key{}.IsZero())
+}
+
+func TestKey_Comparable(t *testing.T) {
+	j1, _ := jid.NewWithString("ortuman@jackal.im/yard", false)
+	j2, _ := jid.NewWithString("ortuman@jackal.im", false)
+	j3, _ := jid.NewWithString("jackal.im/yard", false)
+
+	require.Equal(t, j1.ToBareJID().Key(), j1.Key().Bare())
+	require.True(t, j1.Key().Bare() == j2.Key())
+	require.True(t, j1.Key().Domain() == j2.Key().Domain())
+	require.True(t, j3.Key().Domain() == j1.Key().Domain())
+	require.False(t, j1.Key() == j2.Key())
+}
+
+func TestKey_Matches(t *testing.T) {
+	var jids = []string{
+		"ortuman@jackal.im/yard",
+		"ortuman@jackal.im/balcony",
+		"ortuman@jackal.im",
+		"noelia@jackal.im/yard",
+		"jackal.im",
+		"jackal.im/yard",
+		"example.org",
+	}
+	for _, s1 := range jids {
+		for _, s2 := range jids {
+			j1, _ := jid.NewWithString(s1, false)
+			j2, _ := jid.NewWithString(s2, false)
+			require.Equal(t, j1.Matches(j2), j1.Key().Matches(j2.Key()), "%s - %s", s1, s2)
+			require.Equal(t, j1.MatchesWithOptions(j2, jid.MatchesBare), j1.Key().MatchesWithOptions(j2.Key(), jid.MatchesBare))
+		}
+	}
+}
+
+func BenchmarkKey_Bare(b *testing.B) {
+	k, _ := jid.ParseKey("ortuman@jackal.im/yard")
+	m := map[jid.Key]int{k.Bare(): 1}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m[k.Bare()]
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jid
+
+// Map is a map of values indexed by JID key.
+// The zero value is an empty map ready to use.
+// A Map is not safe for concurrent use.
+type Map[T any] struct {
+	m           map[Key]T
+	serverFulls int // number of full server JID entries (domain/resource)
+}
+
+// Set associates v to k.
+func (m *Map[T]) Set(k Key, v T) {
+	if m.m == nil {
+		m.m = make(map[Key]T)
+	}
+	if _, ok := m.m[k]; !ok && k.IsServer() && k.IsFull() {
+		m.serverFulls++
+	}
+	m.m[k] = v
+}
+
+// Get returns the value exactly associated to k.
+func (m *Map[T]) Get(k Key) (T, bool) {
+	v, ok := m.m[k]
+	return v, ok
+}
+
+// Delete removes k entry.
+func (m *Map[T]) Delete(k Key) {
+	if _, ok := m.m[k]; !ok {
+		return
+	}
+	if k.IsServer() && k.IsFull() {
+		m.serverFulls--
+	}
+	delete(m.m, k)
+}
+
+// Len returns the number of map entries.
+func (m *Map[T]) Len() int {
+	return len(m.m)
+}
+
+// Match returns the value associated to the most specific key matching k, as stated by Key.Matches.
+// Full, full server, bare and domain keys are looked up in that order.
+func (m *Map[T]) Match(k Key) (T, bool) {
+	if v, ok := m.m[k]; ok {
+		return v, true
+	}
+	if k.IsFull() && !k.IsServer() {
+		if m.serverFulls > 0 {
+			sk := Key{s: k.domain() + "/" + k.Resource(), domainEnd: k.domainEnd - k.domainStart}
+			if v, ok := m.m[sk]; ok {
+				return v, true
+			}
+		}
+		if v, ok := m.m[k.Bare()]; ok {
+			return v, true
+		}
+	}
+	if !k.IsServer() || k.IsFull() {
+		if v, ok := m.m[k.Domain()]; ok {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// Range calls f sequentially for each map entry. If f returns false, range stops the iteration.
+func (m *Map[T]) Range(f func(k Key, v T) bool) {
+	for k, v := range m.m {
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+// RangeMatching calls f sequentially for each entry whose key is matched by pattern.
+// If f returns false, range stops the iteration.
+func (m *Map[T]) RangeMatching(pattern Key, f func(k Key, v T) bool) {
+	if pattern.IsFull() && !pattern.IsServer() {
+		if v, ok := m.m[pattern]; ok {
+			f(pattern, v)
+		}
+		return
+	}
+	for k, v := range m.m {
+		if pattern.Matches(k) && !f(k, v) {
+			return
+		}
+	}
+}
+
+// Set is a set of JID keys.
+// The zero value is an empty set ready to use.
+// A Set is not safe for concurrent use.
+type Set struct {
+	m Map[struct{}]
+}
+
+// Add adds k to the set.
+func (s *Set) Add(k Key) {
+	s.m.Set(k, struct{}{})
+}
+
+// Remove removes k from the set.
+func (s *Set) Remove(k Key) {
+	s.m.Delete(k)
+}
+
+// Contains tells whether k is part of the set.
+func (s *Set) Contains(k Key) bool {
+	_, ok := s.m.Get(k)
+	return ok
+}
+
+// Match tells whether any of the set keys matches k, as stated by Key.Matches.
+func (s *Set) Match(k Key) bool {
+	_, ok := s.m.Match(k)
+	return ok
+}
+
+// Len returns the number of keys contained in the set.
+func (s *Set) Len() int {
+	return s.m.Len()
+}
+
+// Keys returns all set keys in no particular order.
+func (s *Set) Keys() []Key {
+	keys := make([]Key, 0, s.m.Len())
+	s.m.Range(func(k Key, _ struct{}) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
@@ -0,0 +1,118 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jid_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza/jid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMap_Match(t *testing.T) {
+	var m jid.Map[string]
+	m.Set(testKey(t, "ortuman@jackal.im/yard"), "full")
+	m.Set(testKey(t, "noelia@jackal.im"), "bare")
+	m.Set(testKey(t, "example.org"), "domain")
+	m.Set(testKey(t, "jackal.im/balcony"), "server-full")
+
+	var tcs = []struct {
+		jid      string
+		expected string
+		found    bool
+	}{
+		{jid: "ortuman@jackal.im/yard", expected: "full", found: true},
+		{jid: "ortuman@jackal.im/balcony", expected: "server-full", found: true},
+		{jid: "ortuman@jackal.im", found: false},
+		{jid: "noelia@jackal.im/yard", expected: "bare", found: true},
+		{jid: "noelia@jackal.im", expected: "bare", found: true},
+		{jid: "romeo@example.org/garden", expected: "domain", found: true},
+		{jid: "example.org", expected: "domain", found: true},
+		{jid: "jackal.im", found: false},
+	}
+	for _, tc := range tcs {
+		v, ok := m.Match(testKey(t, tc.jid))
+		require.Equal(t, tc.found, ok, tc.jid)
+		require.Equal(t, tc.expected, v, tc.jid)
+	}
+	v, ok := m.Get(testKey(t, "noelia@jackal.im"))
+	require.True(t, ok)
+	require.Equal(t, "bare", v)
+
+	m.Delete(testKey(t, "jackal.im/balcony"))
+	_, ok = m.Match(testKey(t, "ortuman@jackal.im/balcony"))
+	require.False(t, ok)
+	require.Equal(t, 3, m.Len())
+}
+
+func TestMap_RangeMatching(t *testing.T) {
+	var m jid.Map[int]
+	m.Set(testKey(t, "ortuman@jackal.im/yard"), 1)
+	m.Set(testKey(t, "ortuman@jackal.im/balcony"), 2)
+	m.Set(testKey(t, "noelia@jackal.im/yard"), 3)
+	m.Set(testKey(t, "romeo@example.org/garden"), 4)
+
+	collect := func(pattern string) []int {
+		var vs []int
+		m.RangeMatching(testKey(t, pattern), func(_ jid.Key, v int) bool {
+			vs = append(vs, v)
+			return true
+		})
+		sort.Ints(vs)
+		return vs
+	}
+	require.Equal(t, []int{1, 2}, collect("ortuman@jackal.im"))
+	require.Equal(t, []int{2}, collect("ortuman@jackal.im/balcony"))
+	require.Equal(t, []int{1, 2, 3}, collect("jackal.im"))
+	require.Equal(t, []int{1, 3}, collect("jackal.im/yard"))
+	require.Nil(t, collect("juliet@capulet.lit"))
+}
+
+func TestSet(t *testing.T) {
+	var s jid.Set
+	s.Add(testKey(t, "ortuman@jackal.im"))
+	s.Add(testKey(t, "example.org"))
+	s.Add(testKey(t, "example.org"))
+
+	require.Equal(t, 2, s.Len())
+	require.True(t, s.Contains(testKey(t, "ortuman@jackal.im")))
+	require.False(t, s.Contains(testKey(t, "ortuman@jackal.im/yard")))
+	require.True(t, s.Match(testKey(t, "ortuman@jackal.im/yard")))
+	require.True(t, s.Match(testKey(t, "romeo@example.org/garden")))
+	require.False(t, s.Match(testKey(t, "noelia@jackal.im/yard")))
+
+	s.Remove(testKey(t, "example.org"))
+	require.Equal(t, []jid.Key{testKey(t, "ortuman@jackal.im")}, s.Keys())
+}
+
+func testKey(t *testing.T, s string) jid.Key {
+	k, err := jid.ParseKey(s)
+	require.Nil(t, err)
+	return k
+}
+
+func BenchmarkMap_Match(b *testing.B) {
+	var m jid.Map[int]
+	bare, _ := jid.ParseKey("ortuman@jackal.im")
+	m.Set(bare, 1)
+	k, _ := jid.ParseKey("ortuman@jackal.im/yard")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = m.Match(k)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jid
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// NewFromProto constructs a JID from its protobuf representation.
+// This construction allows the caller to specify if stringprep should be applied or not.
+func NewFromProto(pb *PBJID, skipStringPrep bool) (*JID, error) {
+	if len(pb.GetDomain()) == 0 && len(pb.GetNode()) == 0 && len(pb.GetResource()) == 0 {
+		return &JID{}, nil
+	}
+	return New(pb.GetNode(), pb.GetDomain(), pb.GetResource(), skipStringPrep)
+}
+
+// Proto returns the JID protobuf representation.
+func (j *JID) Proto() *PBJID {
+	return &PBJID{
+		Node:     j.node,
+		Domain:   j.domain,
+		Resource: j.resource,
+	}
+}
+
+// MarshalText satisfies encoding.TextMarshaler interface.
+func (j *JID) MarshalText() ([]byte, error) {
+	return []byte(j.String()), nil
+}
+
+// UnmarshalText satisfies encoding.TextUnmarshaler interface.
+func (j *JID) UnmarshalText(text []byte) error {
+	j2, err := NewWithString(string(text), false)
+	if err != nil {
+		return err
+	}
+	*j = *j2
+	return nil
+}
+
+// MarshalBinary satisfies encoding.BinaryMarshaler interface.
+func (j *JID) MarshalBinary() ([]byte, error) {
+	return proto.Marshal(j.Proto())
+}
+
+// UnmarshalBinary satisfies encoding.BinaryUnmarshaler interface.
+func (j *JID) UnmarshalBinary(data []byte) error {
+	var pb PBJID
+	if err := proto.Unmarshal(data, &pb); err != nil {
+		return err
+	}
+	j2, err := NewFromProto(&pb, false)
+	if err != nil {
+		return err
+	}
+	*j = *j2
+	return nil
+}
+
+// MarshalJSON satisfies json.Marshaler interface.
+func (j *JID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.String())
+}
+
+// UnmarshalJSON satisfies json.Unmarshaler interface.
+func (j *JID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return j.UnmarshalText([]byte(s))
+}
+
+// Scan satisfies sql.Scanner interface.
+func (j *JID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*j = JID{}
+		return nil
+	case string:
+		return j.UnmarshalText([]byte(v))
+	case []byte:
+		return j.UnmarshalText(v)
+	default:
+		return fmt.Errorf("jid: cannot scan %T into JID", src)
+	}
+}
+
+// Value satisfies driver.Valuer interface.
+// A nil or zero JID is stored as NULL, so that it can be scanned back.
+func (j *JID) Value() (driver.Value, error) {
+	if j == nil || *j == (JID{}) {
+		return nil, nil
+	}
+	return j.String(), nil
+}
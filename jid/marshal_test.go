@@ -0,0 +1,147 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jid_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza/jid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	_ encoding.TextMarshaler     = (*jid.JID)(nil)
+	_ encoding.TextUnmarshaler   = (*jid.JID)(nil)
+	_ encoding.BinaryMarshaler   = (*jid.JID)(nil)
+	_ encoding.BinaryUnmarshaler = (*jid.JID)(nil)
+	_ json.Marshaler             = (*jid.JID)(nil)
+	_ json.Unmarshaler           = (*jid.JID)(nil)
+	_ sql.Scanner                = (*jid.JID)(nil)
+	_ driver.Valuer              = (*jid.JID)(nil)
+)
+
+func TestJID_MarshalText(t *testing.T) {
+	j, _ := jid.NewWithString("ortuman@jackal.im/yard", false)
+
+	b, err := j.MarshalText()
+	require.Nil(t, err)
+	require.Equal(t, "ortuman@jackal.im/yard", string(b))
+
+	var j2 jid.JID
+	require.Nil(t, j2.UnmarshalText([]byte("ORTUMAN@jackal.im/yard")))
+	require.Equal(t, "ortuman@jackal.im/yard", j2.String())
+
+	require.NotNil(t, j2.UnmarshalText([]byte("ortuman@")))
+}
+
+func TestJID_MarshalBinary(t *testing.T) {
+	j, _ := jid.NewWithString("ortuman@jackal.im/yard", false)
+
+	b, err := j.MarshalBinary()
+	require.Nil(t, err)
+
+	var j2 jid.JID
+	require.Nil(t, j2.UnmarshalBinary(b))
+	require.Equal(t, j.String(), j2.String())
+
+	// stringprep is applied on decode
+	b, _ = proto.Marshal(&jid.PBJID{Node: "ORTUMAN", Domain: "jackal.im"})
+	require.Nil(t, j2.UnmarshalBinary(b))
+	require.Equal(t, "ortuman@jackal.im", j2.String())
+}
+
+func TestJID_MarshalJSON(t *testing.T) {
+	type account struct {
+		JID   *jid.JID `json:"jid"`
+		Owner *jid.JID `json:"owner"`
+	}
+	j, _ := jid.NewWithString("ortuman@jackal.im", false)
+
+	b, err := json.Marshal(account{JID: j})
+	require.Nil(t, err)
+	require.Equal(t, `{"jid":"ortuman@jackal.im","owner":null}`, string(b))
+
+	var acc account
+	require.Nil(t, json.Unmarshal([]byte(`{"jid":"Noelia@jackal.im/yard","owner":null}`), &acc))
+	require.Equal(t, "noelia@jackal.im/yard", acc.JID.String())
+	require.Nil(t, acc.Owner)
+
+	require.NotNil(t, json.Unmarshal([]byte(`{"jid":"noelia@"}`), &acc))
+	require.NotNil(t, json.Unmarshal([]byte(`{"jid":1}`), &acc))
+}
+
+func TestJID_SQL(t *testing.T) {
+	j, _ := jid.NewWithString("ortuman@jackal.im/yard", false)
+
+	v, err := j.Value()
+	require.Nil(t, err)
+	require.Equal(t, "ortuman@jackal.im/yard", v)
+
+	var j2 jid.JID
+	require.Nil(t, j2.Scan("ortuman@jackal.im"))
+	require.Equal(t, "ortuman@jackal.im", j2.String())
+
+	require.Nil(t, j2.Scan([]byte("noelia@jackal.im")))
+	require.Equal(t, "noelia@jackal.im", j2.String())
+
+	require.Nil(t, j2.Scan(nil))
+	require.Equal(t, "", j2.String())
+
+	require.NotNil(t, j2.Scan(1))
+}
+
+func TestJID_SQLNil(t *testing.T) {
+	var j *jid.JID
+
+	v, err := j.Value()
+
+	require.Nil(t, err)
+	require.Nil(t, v)
+}
+
+func TestJID_SQLZeroRoundTrip(t *testing.T) {
+	// given
+	var j jid.JID
+	require.Nil(t, j.Scan(nil))
+
+	// when
+	v, err := j.Value()
+
+	// then
+	require.Nil(t, err)
+	require.Nil(t, v)
+
+	var j2 jid.JID
+	require.Nil(t, j2.Scan(v))
+	require.Equal(t, j, j2)
+}
+
+func TestJID_Proto(t *testing.T) {
+	j, _ := jid.NewWithString("ortuman@jackal.im/yard", false)
+
+	pb := j.Proto()
+	require.Equal(t, "ortuman", pb.GetNode())
+	require.Equal(t, "jackal.im", pb.GetDomain())
+	require.Equal(t, "yard", pb.GetResource())
+
+	j2, err := jid.NewFromProto(pb, false)
+	require.Nil(t, err)
+	require.Equal(t, j.String(), j2.String())
+}
@@ -0,0 +1,287 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jid
+
+import "sync"
+
+// Trie is a routing index that stores values under domain, node and resource levels.
+// It's safe to use from multiple goroutines.
+type Trie[T any] struct {
+	mu      sync.RWMutex
+	domains map[string]*trieLevel[T]
+	size    int
+}
+
+// trieLevel represents a domain or a node level.
+// For a domain level, resources contains the full server JID entries.
+type trieLevel[T any] struct {
+	value     T
+	hasValue  bool
+	nodes     map[string]*trieLevel[T]
+	resources map[string]T
+}
+
+func (l *trieLevel[T]) isEmpty() bool {
+	return !l.hasValue && len(l.nodes) == 0 && len(l.resources) == 0
+}
+
+// NewTrie returns a new initialized routing trie.
+func NewTrie[T any]() *Trie[T] {
+	return &Trie[T]{
+		domains: make(map[string]*trieLevel[T]),
+	}
+}
+
+// Put stores v under j.
+func (t *Trie[T]) Put(j *JID, v T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	dl := t.domains[j.domain]
+	if dl == nil {
+		dl = &trieLevel[T]{}
+		t.domains[j.domain] = dl
+	}
+	l := dl
+	if len(j.node) > 0 {
+		l = dl.nodes[j.node]
+		if l == nil {
+			l = &trieLevel[T]{}
+			if dl.nodes == nil {
+				dl.nodes = make(map[string]*trieLevel[T])
+			}
+			dl.nodes[j.node] = l
+		}
+	}
+	if len(j.resource) == 0 {
+		if !l.hasValue {
+			t.size++
+		}
+		l.value, l.hasValue = v, true
+		return
+	}
+	if l.resources == nil {
+		l.resources = make(map[string]T)
+	}
+	if _, ok := l.resources[j.resource]; !ok {
+		t.size++
+	}
+	l.resources[j.resource] = v
+}
+
+// Get returns the value stored exactly under j.
+func (t *Trie[T]) Get(j *JID) (T, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var zero T
+	l := t.level(j.domain, j.node)
+	if l == nil {
+		return zero, false
+	}
+	if len(j.resource) == 0 {
+		return l.value, l.hasValue
+	}
+	v, ok := l.resources[j.resource]
+	return v, ok
+}
+
+// Delete removes the value stored under j, reporting whether it was present.
+func (t *Trie[T]) Delete(j *JID) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	dl := t.domains[j.domain]
+	l := t.level(j.domain, j.node)
+	if l == nil {
+		return false
+	}
+	if len(j.resource) == 0 {
+		if !l.hasValue {
+			return false
+		}
+		var zero T
+		l.value, l.hasValue = zero, false
+	} else {
+		if _, ok := l.resources[j.resource]; !ok {
+			return false
+		}
+		delete(l.resources, j.resource)
+	}
+	t.size--
+
+	// prune empty levels
+	if l != dl && l.isEmpty() {
+		delete(dl.nodes, j.node)
+	}
+	if dl.isEmpty() {
+		delete(t.domains, j.domain)
+	}
+	return true
+}
+
+// Len returns the number of stored values.
+func (t *Trie[T]) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.size
+}
+
+// LongestMatch returns the value stored under the most specific JID matching j.
+// That is, j full JID, its domain and resource, its bare JID or its domain, in that order.
+func (t *Trie[T]) LongestMatch(j *JID) (T, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var zero T
+	dl := t.domains[j.domain]
+	if dl == nil {
+		return zero, false
+	}
+	l := dl
+	if len(j.node) > 0 {
+		l = dl.nodes[j.node]
+	}
+	if l != nil && len(j.resource) > 0 {
+		if v, ok := l.resources[j.resource]; ok {
+			return v, true
+		}
+	}
+	if l != dl && len(j.resource) > 0 {
+		if v, ok := dl.resources[j.resource]; ok {
+			return v, true
+		}
+	}
+	if l != nil && l.hasValue {
+		return l.value, true
+	}
+	if dl.hasValue {
+		return dl.value, true
+	}
+	return zero, false
+}
+
+// Match returns all values whose JID matches j based on matching options.
+// Those parts not included in options are treated as wildcards.
+// For instance, MatchesBare options returns all values stored under j bare JID and any of its resources.
+// Returned values are in no particular order.
+func (t *Trie[T]) Match(j *JID, options MatchingOptions) []T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var values []T
+	t.walk(j, options, func(_ *JID, v T) bool {
+		values = append(values, v)
+		return true
+	})
+	return values
+}
+
+// RangeMatching calls f sequentially for each stored JID matching j based on matching options.
+// If f returns false, range stops the iteration.
+// Trie must not be modified from within f.
+func (t *Trie[T]) RangeMatching(j *JID, options MatchingOptions, f func(j *JID, v T) bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	t.walk(j, options, f)
+}
+
+// RangeResources calls f sequentially for each resource stored under j bare JID.
+// If f returns false, range stops the iteration.
+// Trie must not be modified from within f.
+func (t *Trie[T]) RangeResources(j *JID, f func(resource string, v T) bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	l := t.level(j.domain, j.node)
+	if l == nil {
+		return
+	}
+	for res, v := range l.resources {
+		if !f(res, v) {
+			return
+		}
+	}
+}
+
+func (t *Trie[T]) level(domain, node string) *trieLevel[T] {
+	dl := t.domains[domain]
+	if dl == nil || len(node) == 0 {
+		return dl
+	}
+	return dl.nodes[node]
+}
+
+func (t *Trie[T]) walk(j *JID, options MatchingOptions, f func(j *JID, v T) bool) bool {
+	if options&MatchesDomain > 0 {
+		dl := t.domains[j.domain]
+		if dl == nil {
+			return true
+		}
+		return walkDomain(j.domain, dl, j, options, f)
+	}
+	for domain, dl := range t.domains {
+		if !walkDomain(domain, dl, j, options, f) {
+			return false
+		}
+	}
+	return true
+}
+
+func walkDomain[T any](domain string, dl *trieLevel[T], j *JID, options MatchingOptions, f func(j *JID, v T) bool) bool {
+	if options&MatchesNode > 0 {
+		l := dl
+		if len(j.node) > 0 {
+			l = dl.nodes[j.node]
+		}
+		if l == nil {
+			return true
+		}
+		return walkLevel(domain, j.node, l, j, options, f)
+	}
+	if !walkLevel(domain, "", dl, j, options, f) {
+		return false
+	}
+	for node, l := range dl.nodes {
+		if !walkLevel(domain, node, l, j, options, f) {
+			return false
+		}
+	}
+	return true
+}
+
+func walkLevel[T any](domain, node string, l *trieLevel[T], j *JID, options MatchingOptions, f func(j *JID, v T) bool) bool {
+	if options&MatchesResource > 0 {
+		if len(j.resource) == 0 {
+			if l.hasValue {
+				return f(&JID{node: node, domain: domain}, l.value)
+			}
+			return true
+		}
+		if v, ok := l.resources[j.resource]; ok {
+			return f(&JID{node: node, domain: domain, resource: j.resource}, v)
+		}
+		return true
+	}
+	if l.hasValue && !f(&JID{node: node, domain: domain}, l.value) {
+		return false
+	}
+	for res, v := range l.resources {
+		if !f(&JID{node: node, domain: domain, resource: res}, v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jid_test
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza/jid"
+	"github.com/stretchr/testify/require"
+)
+
+var trieJIDs = []string{
+	"ortuman@jackal.im/yard",
+	"ortuman@jackal.im/balcony",
+	"ortuman@jackal.im",
+	"noelia@jackal.im/yard",
+	"jackal.im",
+	"jackal.im/yard",
+	"romeo@example.org/garden",
+	"example.org/yard",
+}
+
+func TestTrie_PutGetDelete(t *testing.T) {
+	tr := testTrie(t)
+	require.Equal(t, len(trieJIDs), tr.Len())
+
+	for _, s := range trieJIDs {
+		v, ok := tr.Get(testJID(t, s))
+		require.True(t, ok, s)
+		require.Equal(t, s, v)
+	}
+	_, ok := tr.Get(testJID(t, "noelia@jackal.im"))
+	require.False(t, ok)
+
+	tr.Put(testJID(t, "ortuman@jackal.im/yard"), "replaced")
+	v, _ := tr.Get(testJID(t, "ortuman@jackal.im/yard"))
+	require.Equal(t, "replaced", v)
+	require.Equal(t, len(trieJIDs), tr.Len())
+
+	for _, s := range trieJIDs {
+		require.True(t, tr.Delete(testJID(t, s)), s)
+		require.False(t, tr.Delete(testJID(t, s)), s)
+	}
+	require.Equal(t, 0, tr.Len())
+}
+
+func TestTrie_LongestMatch(t *testing.T) {
+	tr := testTrie(t)
+
+	var tcs = []struct {
+		jid      string
+		expected string
+		found    bool
+	}{
+		{jid: "ortuman@jackal.im/yard", expected: "ortuman@jackal.im/yard", found: true},
+		{jid: "ortuman@jackal.im/hall", expected: "ortuman@jackal.im", found: true},
+		{jid: "noelia@jackal.im/hall", expected: "jackal.im", found: true},
+		{jid: "juliet@jackal.im/yard", expected: "jackal.im/yard", found: true},
+		{jid: "noelia@jackal.im/yard", expected: "noelia@jackal.im/yard", found: true},
+		{jid: "juliet@example.org/yard", expected: "example.org/yard", found: true},
+		{jid: "juliet@example.org/garden", found: false},
+		{jid: "jackal.im/hall", expected: "jackal.im", found: true},
+		{jid: "romeo@example.org/balcony", found: false},
+		{jid: "capulet.lit", found: false},
+	}
+	for _, tc := range tcs {
+		v, ok := tr.LongestMatch(testJID(t, tc.jid))
+		require.Equal(t, tc.found, ok, tc.jid)
+		require.Equal(t, tc.expected, v, tc.jid)
+	}
+}
+
+func TestTrie_LongestMatchMap(t *testing.T) {
+	tr := testTrie(t)
+
+	var m jid.Map[string]
+	for _, s := range trieJIDs {
+		m.Set(testJID(t, s).Key(), s)
+	}
+	lookups := append([]string{"juliet@jackal.im/yard", "juliet@example.org", "example.org/garden"}, trieJIDs...)
+	for _, s := range lookups {
+		j := testJID(t, s)
+		expected, expectedOK := m.Match(j.Key())
+		v, ok := tr.LongestMatch(j)
+		require.Equal(t, expectedOK, ok, s)
+		require.Equal(t, expected, v, s)
+	}
+}
+
+func TestTrie_Match(t *testing.T) {
+	tr := testTrie(t)
+
+	lookups := append([]string{"noelia@jackal.im", "capulet.lit/yard", "juliet@capulet.lit"}, trieJIDs...)
+	for _, s := range lookups {
+		j := testJID(t, s)
+		for opts := jid.MatchingOptions(0); opts <= jid.MatchesFull; opts++ {
+			var expected []string
+			for _, s2 := range trieJIDs {
+				if j.MatchesWithOptions(testJID(t, s2), opts) {
+					expected = append(expected, s2)
+				}
+			}
+			values := tr.Match(j, opts)
+			sort.Strings(expected)
+			sort.Strings(values)
+			require.Equal(t, expected, values, "%s (%d)", s, opts)
+		}
+	}
+}
+
+func TestTrie_RangeResources(t *testing.T) {
+	tr := testTrie(t)
+
+	var resources []string
+	tr.RangeResources(testJID(t, "ortuman@jackal.im/hall"), func(res string, v string) bool {
+		resources = append(resources, res)
+		return true
+	})
+	sort.Strings(resources)
+	require.Equal(t, []string{"balcony", "yard"}, resources)
+
+	var n int
+	tr.RangeMatching(testJID(t, "jackal.im"), jid.MatchesDomain, func(j *jid.JID, v string) bool {
+		require.Equal(t, v, j.String())
+		n++
+		return n < 2
+	})
+	require.Equal(t, 2, n)
+}
+
+func TestTrie_Concurrent(t *testing.T) {
+	tr := jid.NewTrie[int]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			j, _ := jid.New("ortuman", "jackal.im", strconv.Itoa(i), true)
+			for n := 0; n < 100; n++ {
+				tr.Put(j, n)
+				_ = tr.Match(j.ToBareJID(), jid.MatchesBare)
+				_, _ = tr.LongestMatch(j)
+				tr.Delete(j)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, 0, tr.Len())
+}
+
+func testTrie(t *testing.T) *jid.Trie[string] {
+	tr := jid.NewTrie[string]()
+	for _, s := range trieJIDs {
+		tr.Put(testJID(t, s), s)
+	}
+	return tr
+}
+
+func testJID(t *testing.T, s string) *jid.JID {
+	j, err := jid.NewWithString(s, false)
+	require.Nil(t, err)
+	return j
+}
@@ -0,0 +1,270 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppuri
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jackal-xmpp/stravaganza/jid"
+)
+
+const scheme = "xmpp:"
+
+// Param represents a URI query key-value pair.
+type Param struct {
+	Key   string
+	Value string
+}
+
+// URI represents an XMPP URI as defined in RFC 5122.
+type URI struct {
+	// Authority is the account to be used when processing the URI.
+	// If nil, the user's configured account is assumed.
+	Authority *jid.JID
+
+	// JID is the target entity address.
+	JID *jid.JID
+
+	// Action is the query type (ie. 'message', 'subscribe', 'join').
+	Action string
+
+	// Params contains the action key-value pairs, in order of appearance.
+	Params []Param
+
+	// Fragment is the URI fragment identifier.
+	Fragment string
+}
+
+// FromJID returns a URI pointing to j.
+func FromJID(j *jid.JID) *URI {
+	return &URI{JID: j}
+}
+
+// Parse parses an XMPP URI or IRI.
+// Node, domain and resource values are prepared by applying stringprep.
+func Parse(s string) (*URI, error) {
+	if len(s) < len(scheme) || !strings.EqualFold(s[:len(scheme)], scheme) {
+		return nil, errors.New("xmppuri: missing 'xmpp:' scheme")
+	}
+	s = s[len(scheme):]
+
+	var u URI
+	var err error
+	if i := strings.IndexByte(s, '#'); i != -1 {
+		if u.Fragment, err = url.PathUnescape(s[i+1:]); err != nil {
+			return nil, err
+		}
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '?'); i != -1 {
+		if err := u.parseQuery(s[i+1:]); err != nil {
+			return nil, err
+		}
+		s = s[:i]
+	}
+	if strings.HasPrefix(s, "//") {
+		s = s[2:]
+		auth := s
+		s = ""
+		if i := strings.IndexByte(auth, '/'); i != -1 {
+			auth, s = auth[:i], auth[i+1:]
+		}
+		if u.Authority, err = parseJID(auth); err != nil {
+			return nil, err
+		}
+		if u.Authority.IsServer() || u.Authority.IsFull() {
+			return nil, errors.New("xmppuri: authority must be a bare JID")
+		}
+	}
+	if len(s) > 0 {
+		if u.JID, err = parseJID(s); err != nil {
+			return nil, err
+		}
+	}
+	if u.Authority == nil && u.JID == nil {
+		return nil, errors.New("xmppuri: missing JID")
+	}
+	return &u, nil
+}
+
+// Param returns the value associated to key, or empty string if not present.
+func (u *URI) Param(key string) string {
+	for _, p := range u.Params {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// String returns the URI representation, with all non-ASCII characters percent-encoded.
+func (u *URI) String() string {
+	return u.format(false)
+}
+
+// IRI returns the IRI representation, in which non-ASCII characters are left as is.
+func (u *URI) IRI() string {
+	return u.format(true)
+}
+
+func (u *URI) format(iri bool) string {
+	var sb strings.Builder
+	sb.WriteString(scheme)
+	if u.Authority != nil {
+		sb.WriteString("//")
+		writeJID(&sb, u.Authority, iri)
+		if u.JID != nil {
+			sb.WriteByte('/')
+		}
+	}
+	if u.JID != nil {
+		writeJID(&sb, u.JID, iri)
+	}
+	if len(u.Action) > 0 || len(u.Params) > 0 {
+		sb.WriteByte('?')
+		escape(&sb, u.Action, isUnreserved, iri)
+		for _, p := range u.Params {
+			sb.WriteByte(';')
+			escape(&sb, p.Key, isUnreserved, iri)
+			sb.WriteByte('=')
+			escape(&sb, p.Value, isUnreserved, iri)
+		}
+	}
+	if len(u.Fragment) > 0 {
+		sb.WriteByte('#')
+		escape(&sb, u.Fragment, isFragmentChar, iri)
+	}
+	return sb.String()
+}
+
+func (u *URI) parseQuery(q string) error {
+	pairs := strings.Split(q, ";")
+	action, err := url.PathUnescape(pairs[0])
+	if err != nil {
+		return err
+	}
+	u.Action = action
+	for _, pair := range pairs[1:] {
+		if len(pair) == 0 {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("xmppuri: malformed query pair: %s", pair)
+		}
+		key, err := url.PathUnescape(k)
+		if err != nil {
+			return err
+		}
+		value, err := url.PathUnescape(v)
+		if err != nil {
+			return err
+		}
+		u.Params = append(u.Params, Param{Key: key, Value: value})
+	}
+	return nil
+}
+
+func parseJID(s string) (*jid.JID, error) {
+	var node, domain, resource string
+	if i := strings.IndexByte(s, '/'); i != -1 {
+		s, resource = s[:i], s[i+1:]
+		if len(resource) == 0 {
+			return nil, errors.New("xmppuri: empty resource")
+		}
+	}
+	if i := strings.IndexByte(s, '@'); i != -1 {
+		node, s = s[:i], s[i+1:]
+		if len(node) == 0 {
+			return nil, errors.New("xmppuri: empty node")
+		}
+	}
+	domain = s
+	if len(domain) == 0 {
+		return nil, errors.New("xmppuri: empty domain")
+	}
+	var err error
+	if node, err = url.PathUnescape(node); err != nil {
+		return nil, err
+	}
+	if domain, err = url.PathUnescape(domain); err != nil {
+		return nil, err
+	}
+	if resource, err = url.PathUnescape(resource); err != nil {
+		return nil, err
+	}
+	return jid.New(node, domain, resource, false)
+}
+
+func writeJID(sb *strings.Builder, j *jid.JID, iri bool) {
+	if len(j.Node()) > 0 {
+		escape(sb, j.Node(), isNodeChar, iri)
+		sb.WriteByte('@')
+	}
+	if strings.HasPrefix(j.Domain(), "[") {
+		sb.WriteString(j.Domain()) // IP literal
+	} else {
+		escape(sb, j.Domain(), isHostChar, iri)
+	}
+	if len(j.Resource()) > 0 {
+		sb.WriteByte('/')
+		escape(sb, j.Resource(), isResourceChar, iri)
+	}
+}
+
+const upperHex = "0123456789ABCDEF"
+
+func escape(sb *strings.Builder, s string, allowed func(c byte) bool, iri bool) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if allowed(c) || (iri && c >= 0x80) {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteByte('%')
+		sb.WriteByte(upperHex[c>>4])
+		sb.WriteByte(upperHex[c&0x0f])
+	}
+}
+
+// unreserved = ALPHA / DIGIT / "-" / "." / "_" / "~"
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// nodeallow = "!" / "$" / "(" / ")" / "*" / "+" / "," / ";" / "="
+func isNodeChar(c byte) bool {
+	return isUnreserved(c) || strings.IndexByte("!$()*+,;=", c) != -1
+}
+
+// resid = *( unreserved / pct-encoded / resallow )
+// resallow = "!" / "$" / "&" / "'" / "(" / ")" / "*" / "+" / "," / ";" / "=" / ":" / "@"
+func isResourceChar(c byte) bool {
+	return isUnreserved(c) || strings.IndexByte("!$&'()*+,;=:@", c) != -1
+}
+
+// reg-name = *( unreserved / pct-encoded / sub-delims )
+func isHostChar(c byte) bool {
+	return isUnreserved(c) || strings.IndexByte("!$&'()*+,;=", c) != -1
+}
+
+// fragment = *( pchar / "/" / "?" )
+func isFragmentChar(c byte) bool {
+	return isUnreserved(c) || strings.IndexByte("!$&'()*+,;=:@/?", c) != -1
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppuri
+
+import (
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza/jid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURI_Parse(t *testing.T) {
+	u, err := Parse("xmpp:romeo@montague.net?message;subject=Test%20Message;body=Here%27s%20a%20test%20message")
+	require.Nil(t, err)
+	require.Nil(t, u.Authority)
+	require.Equal(t, "romeo@montague.net", u.JID.String())
+	require.Equal(t, "message", u.Action)
+	require.Equal(t, []Param{
+		{Key: "subject", Value: "Test Message"},
+		{Key: "body", Value: "Here's a test message"},
+	}, u.Params)
+	require.Equal(t, "Here's a test message", u.Param("body"))
+	require.Equal(t, "", u.Param("thread"))
+}
+
+func TestURI_ParseAuthority(t *testing.T) {
+	u, err := Parse("xmpp://guest@example.com/support@example.com?message")
+	require.Nil(t, err)
+	require.Equal(t, "guest@example.com", u.Authority.String())
+	require.Equal(t, "support@example.com", u.JID.String())
+	require.Equal(t, "message", u.Action)
+
+	u2, err := Parse("xmpp://guest@example.com")
+	require.Nil(t, err)
+	require.Equal(t, "guest@example.com", u2.Authority.String())
+	require.Nil(t, u2.JID)
+}
+
+func TestURI_ParseIRI(t *testing.T) {
+	u1, err := Parse("xmpp:ji%C5%99i@%C4%8Dechy.example/v%20Praze")
+	require.Nil(t, err)
+	u2, err := Parse("xmpp:jiři@čechy.example/v%20Praze")
+	require.Nil(t, err)
+
+	require.Equal(t, "jiři@čechy.example/v Praze", u1.JID.String())
+	require.Equal(t, u1.JID.String(), u2.JID.String())
+
+	require.Equal(t, "xmpp:ji%C5%99i@%C4%8Dechy.example/v%20Praze", u1.String())
+	require.Equal(t, "xmpp:jiři@čechy.example/v%20Praze", u1.IRI())
+}
+
+func TestURI_String(t *testing.T) {
+	j, _ := jid.New("darkcave", "chat.shakespeare.lit", "", false)
+	u := FromJID(j)
+	u.Action = "join"
+	u.Params = []Param{{Key: "password", Value: "cauldron burn"}}
+
+	require.Equal(t, "xmpp:darkcave@chat.shakespeare.lit?join;password=cauldron%20burn", u.String())
+
+	auth, _ := jid.New("guest", "example.com", "", false)
+	target, _ := jid.New("support", "example.com", "res/with?chars", false)
+	u2 := &URI{Authority: auth, JID: target, Fragment: "frag ment"}
+
+	require.Equal(t, "xmpp://guest@example.com/support@example.com/res%2Fwith%3Fchars#frag%20ment", u2.String())
+
+	parsed, err := Parse(u2.String())
+	require.Nil(t, err)
+	require.Equal(t, u2, parsed)
+}
+
+func TestURI_ParseErrors(t *testing.T) {
+	uris := []string{
+		"",
+		"http://example.com",
+		"xmpp:",
+		"xmpp:@example.com",
+		"xmpp:romeo@",
+		"xmpp:romeo@montague.net/",
+		"xmpp:romeo@montague.net?message;subject",
+		"xmpp:romeo%ZZ@montague.net",
+		"xmpp://montague.net/juliet@capulet.lit",
+	}
+	for _, s := range uris {
+		_, err := Parse(s)
+		require.NotNil(t, err, s)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stravaganza
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// jsonElement is the element JSON representation, as documented in NewBuilderFromJSON.
+type jsonElement struct {
+	Name       string          `json:"name"`
+	Namespace  string          `json:"namespace,omitempty"`
+	Attributes []jsonAttribute `json:"attributes,omitempty"`
+	Children   []*jsonElement  `json:"children,omitempty"`
+	Text       string          `json:"text,omitempty"`
+	TextNodes  []jsonTextNode  `json:"textNodes,omitempty"`
+}
+
+type jsonAttribute struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+type jsonTextNode struct {
+	Position uint32 `json:"position"`
+	Value    string `json:"value"`
+}
+
+// NewBuilderFromJSON returns an element builder derived from an element JSON representation.
+//
+// The same stable JSON schema is produced when marshaling elements built by this package, IQ, Message and Presence values included:
+//
+//	{
+//	  "name": "message",
+//	  "namespace": "jabber:client",
+//	  "attributes": [{"label": "id", "value": "1234"}],
+//	  "children": [{"name": "body", "text": "Hi!"}],
+//	  "text": "",
+//	  "textNodes": [{"position": 0, "value": ""}]
+//	}
+//
+// 'namespace' is the resolved namespace URI, only present when set by a namespace aware parser.
+// Attributes and children are listed in document order.
+// 'text' contains all element text concatenated, while 'textNodes' is only present in case of mixed content,
+// where 'position' is the number of children preceding each text node.
+// All fields but 'name' are omitted when empty.
+func NewBuilderFromJSON(b []byte) (*Builder, error) {
+	var je jsonElement
+	if err := json.Unmarshal(b, &je); err != nil {
+		return nil, err
+	}
+	return builderFromJSONElement(&je)
+}
+
+func (e *element) MarshalJSON() ([]byte, error) {
+	return json.Marshal(protoToJSONElement(e.pb))
+}
+
+func (e *element) UnmarshalJSON(b []byte) error {
+	bld, err := NewBuilderFromJSON(b)
+	if err != nil {
+		return err
+	}
+	e.pb = bld.buildProtoElement()
+	return nil
+}
+
+// UnmarshalJSON satisfies json.Unmarshaler interface.
+func (iq *IQ) UnmarshalJSON(b []byte) error {
+	bld, err := NewBuilderFromJSON(b)
+	if err != nil {
+		return err
+	}
+	iq2, err := bld.BuildIQ()
+	if err != nil {
+		return err
+	}
+	*iq = *iq2
+	return nil
+}
+
+// UnmarshalJSON satisfies json.Unmarshaler interface.
+func (m *Message) UnmarshalJSON(b []byte) error {
+	bld, err := NewBuilderFromJSON(b)
+	if err != nil {
+		return err
+	}
+	m2, err := bld.BuildMessage()
+	if err != nil {
+		return err
+	}
+	*m = *m2
+	return nil
+}
+
+// UnmarshalJSON satisfies json.Unmarshaler interface.
+func (p *Presence) UnmarshalJSON(b []byte) error {
+	bld, err := NewBuilderFromJSON(b)
+	if err != nil {
+		return err
+	}
+	p2, err := bld.BuildPresence()
+	if err != nil {
+		return err
+	}
+	*p = *p2
+	return nil
+}
+
+func protoToJSONElement(pb *PBElement) *jsonElement {
+	je := &jsonElement{
+		Name:      pb.GetName(),
+		Namespace: pb.GetNamespace(),
+		Text:      pb.GetText(),
+	}
+	for _, attr := range pb.GetAttributes() {
+		je.Attributes = append(je.Attributes, jsonAttribute{Label: attr.Label, Value: attr.Value})
+	}
+	for _, child := range pb.GetElements() {
+		je.Children = append(je.Children, protoToJSONElement(child))
+	}
+	for _, tn := range pb.GetTextNodes() {
+		je.TextNodes = append(je.TextNodes, jsonTextNode{Position: tn.Position, Value: tn.Value})
+	}
+	return je
+}
+
+func builderFromJSONElement(je *jsonElement) (*Builder, error) {
+	if je == nil || len(je.Name) == 0 {
+		return nil, errors.New("stravaganza: missing JSON element name")
+	}
+	b := NewBuilder(je.Name).WithNamespaceURI(je.Namespace)
+	for _, attr := range je.Attributes {
+		b.WithAttribute(attr.Label, attr.Value)
+	}
+	children := make([]Element, 0, len(je.Children))
+	for _, jeChild := range je.Children {
+		cb, err := builderFromJSONElement(jeChild)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, cb.Build())
+	}
+	if len(je.TextNodes) == 0 {
+		return b.WithText(je.Text).WithChildren(children...), nil
+	}
+	// rebuild mixed content
+	var text string
+	textNodes := je.TextNodes
+	for i := 0; i <= len(children); i++ {
+		for len(textNodes) > 0 && int(textNodes[0].Position) == i {
+			b.AppendText(textNodes[0].Value)
+			text += textNodes[0].Value
+			textNodes = textNodes[1:]
+		}
+		if i < len(children) {
+			b.WithChild(children[i])
+		}
+	}
+	if len(textNodes) > 0 {
+		return nil, fmt.Errorf("stravaganza: invalid JSON text node position: %d", textNodes[0].Position)
+	}
+	if len(je.Text) > 0 && je.Text != text {
+		return nil, errors.New("stravaganza: JSON element text doesn't match its text nodes")
+	}
+	return b, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stravaganza
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestElement_MarshalJSON(t *testing.T) {
+	// given
+	el := NewBuilder("message").
+		WithAttribute("type", "chat").
+		WithAttribute("id", "1234").
+		WithChild(
+			NewBuilder("body").
+				WithText("Hi!").
+				Build(),
+		).
+		Build()
+
+	// when
+	b, err := json.Marshal(el)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, `{"name":"message","attributes":[{"label":"type","value":"chat"},{"label":"id","value":"1234"}],"children":[{"name":"body","text":"Hi!"}]}`, string(b))
+}
+
+func TestElement_JSONRoundTrip(t *testing.T) {
+	// given
+	el := NewBuilder("p").
+		WithNamespaceURI("http://www.w3.org/1999/xhtml").
+		WithAttribute("z", "1").
+		WithAttribute("a", "").
+		AppendText("hello ").
+		WithChild(NewBuilder("b").WithText("bold").Build()).
+		AppendText(" world").
+		WithChild(NewBuilder("i").Build()).
+		Build()
+
+	// when
+	b, err := json.Marshal(el)
+	require.Nil(t, err)
+
+	el2 := EmptyElement()
+	err = json.Unmarshal(b, el2)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, el.String(), el2.String())
+	require.Equal(t, el.AllAttributes(), el2.AllAttributes())
+	require.Equal(t, "http://www.w3.org/1999/xhtml", NamespaceURI(el2))
+	require.Equal(t, Nodes(el)[1].Element.String(), Nodes(el2)[1].Element.String())
+	require.Len(t, Nodes(el2), 4)
+}
+
+func TestElement_UnmarshalJSONStanza(t *testing.T) {
+	// given
+	doc := `{"name":"iq","attributes":[{"label":"id","value":"1"},{"label":"type","value":"get"},{"label":"from","value":"ortuman@jackal.im/yard"},{"label":"to","value":"jackal.im"}],"children":[{"name":"ping","attributes":[{"label":"xmlns","value":"urn:xmpp:ping"}]}]}`
+
+	// when
+	var iq IQ
+	err := json.Unmarshal([]byte(doc), &iq)
+
+	var msg Message
+	msgErr := json.Unmarshal([]byte(doc), &msg)
+
+	// then
+	require.Nil(t, err)
+	require.True(t, iq.IsGet())
+	require.Equal(t, "ortuman@jackal.im/yard", iq.FromJID().String())
+	require.NotNil(t, iq.ChildNamespace("ping", "urn:xmpp:ping"))
+	require.NotNil(t, msgErr)
+}
+
+func TestBuilder_FromJSON(t *testing.T) {
+	// given
+	doc := `{"name":"p","children":[{"name":"b"}],"text":"ab","textNodes":[{"position":0,"value":"a"},{"position":1,"value":"b"}]}`
+
+	// when
+	b, err := NewBuilderFromJSON([]byte(doc))
+
+	_, err2 := NewBuilderFromJSON([]byte(`{"text":"no name"}`))
+	_, err3 := NewBuilderFromJSON([]byte(`{"name":"p","textNodes":[{"position":2,"value":"a"}]}`))
+	_, err4 := NewBuilderFromJSON([]byte(`{"name":"p","children":[{"name":"b"}],"text":"xyz","textNodes":[{"position":1,"value":"a"}]}`))
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, "<p>a<b/>b</p>", b.Build().String())
+	require.NotNil(t, err2)
+	require.NotNil(t, err3)
+	require.NotNil(t, err4)
+}
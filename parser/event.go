@@ -0,0 +1,121 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppparser
+
+import (
+	"github.com/jackal-xmpp/stravaganza"
+)
+
+// EventKind defines the kind of parsing event.
+type EventKind int
+
+const (
+	// StartElementEvent is emitted when an element start tag is read.
+	StartElementEvent = EventKind(iota)
+
+	// CharDataEvent is emitted when character data is read inside an element.
+	CharDataEvent
+
+	// EndElementEvent is emitted when an element end tag is read.
+	EndElementEvent
+)
+
+// String returns EventKind string representation.
+func (k EventKind) String() string {
+	switch k {
+	case StartElementEvent:
+		return "start-element"
+	case CharDataEvent:
+		return "char-data"
+	case EndElementEvent:
+		return "end-element"
+	}
+	return "unknown"
+}
+
+// Event represents a single parsing event.
+type Event struct {
+	// Kind is the event kind.
+	Kind EventKind
+
+	// Name is the element name.
+	// Only set for StartElementEvent and EndElementEvent events.
+	Name string
+
+	// Namespace is the element resolved namespace URI.
+	// Only set for StartElementEvent and EndElementEvent events when namespace resolution is enabled.
+	Namespace string
+
+	// Attributes contains the element attributes.
+	// Only set for StartElementEvent events.
+	Attributes []stravaganza.Attribute
+
+	// Text contains the element character data.
+	// Only set for CharDataEvent events.
+	Text string
+
+	// Depth is the nesting level of the event.
+	// Root element start and end events are emitted at depth 0, while its character data is emitted at depth 1.
+	Depth int
+
+	// StartOffset is the input byte offset at which the event token begins.
+	StartOffset int64
+
+	// EndOffset is the input byte offset at which the event token ends.
+	EndOffset int64
+}
+
+// TreeBuilder builds elements from a sequence of parsing events.
+type TreeBuilder struct {
+	stack []*stravaganza.Builder
+}
+
+// NewTreeBuilder returns an empty TreeBuilder instance.
+func NewTreeBuilder() *TreeBuilder {
+	return &TreeBuilder{}
+}
+
+// Push feeds the builder with the next parsing event.
+// Once the root element has been completely read it returns the built element, otherwise nil is returned.
+func (tb *TreeBuilder) Push(ev Event) (stravaganza.Element, error) {
+	switch ev.Kind {
+	case StartElementEvent:
+		b := stravaganza.NewBuilder(ev.Name).
+			WithAttributes(ev.Attributes...).
+			WithNamespaceURI(ev.Namespace)
+		tb.stack = append(tb.stack, b)
+
+	case CharDataEvent:
+		if len(tb.stack) > 0 {
+			tb.stack[len(tb.stack)-1].AppendText(ev.Text)
+		}
+
+	case EndElementEvent:
+		if len(tb.stack) == 0 {
+			return nil, errUnexpectedEnd(ev.Name)
+		}
+		element := tb.stack[len(tb.stack)-1].Build()
+		if element.Name() != ev.Name {
+			return nil, errUnexpectedEnd(ev.Name)
+		}
+		tb.stack = tb.stack[:len(tb.stack)-1]
+
+		if len(tb.stack) == 0 {
+			return element, nil
+		}
+		tb.stack[len(tb.stack)-1].WithChild(element)
+	}
+	return nil, nil
+}
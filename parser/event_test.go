@@ -0,0 +1,174 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppparser
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParser_NextEvent(t *testing.T) {
+	// given
+	docSrc := `<iq id='1' type='get'><q>hi</q></iq>`
+	p := New(strings.NewReader(docSrc), DefaultMode, 1024)
+
+	// when
+	var events []Event
+	for {
+		ev, err := p.NextEvent()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+		events = append(events, ev)
+	}
+
+	// then
+	require.Len(t, events, 5)
+
+	require.Equal(t, StartElementEvent, events[0].Kind)
+	require.Equal(t, "iq", events[0].Name)
+	require.Equal(t, []stravaganza.Attribute{{Label: "id", Value: "1"}, {Label: "type", Value: "get"}}, events[0].Attributes)
+	require.Equal(t, 0, events[0].Depth)
+	require.Equal(t, int64(0), events[0].StartOffset)
+	require.Equal(t, int64(22), events[0].EndOffset)
+
+	require.Equal(t, StartElementEvent, events[1].Kind)
+	require.Equal(t, "q", events[1].Name)
+	require.Equal(t, 1, events[1].Depth)
+
+	require.Equal(t, CharDataEvent, events[2].Kind)
+	require.Equal(t, "hi", events[2].Text)
+	require.Equal(t, 2, events[2].Depth)
+	require.Equal(t, int64(25), events[2].StartOffset)
+	require.Equal(t, int64(27), events[2].EndOffset)
+
+	require.Equal(t, EndElementEvent, events[3].Kind)
+	require.Equal(t, "q", events[3].Name)
+	require.Equal(t, 1, events[3].Depth)
+
+	require.Equal(t, EndElementEvent, events[4].Kind)
+	require.Equal(t, "iq", events[4].Name)
+	require.Equal(t, 0, events[4].Depth)
+	require.Equal(t, int64(len(docSrc)), events[4].EndOffset)
+}
+
+func TestParser_NextEventHeaderBeforeBody(t *testing.T) {
+	// given
+	pr, pw := io.Pipe()
+	defer func() { _ = pw.Close() }()
+
+	go func() {
+		_, _ = io.WriteString(pw, `<message to='noelia@jackal.im' from='ortuman@jackal.im/yard' type='chat'>`)
+	}()
+	p := New(pr, SocketStream, 1024)
+
+	// when
+	ev, err := p.NextEvent()
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, StartElementEvent, ev.Kind)
+	require.Equal(t, "message", ev.Name)
+	require.Equal(t, "noelia@jackal.im", ev.Attributes[0].Value)
+	require.Equal(t, "ortuman@jackal.im/yard", ev.Attributes[1].Value)
+	require.Equal(t, "chat", ev.Attributes[2].Value)
+}
+
+func TestParser_NextEventStream(t *testing.T) {
+	// given
+	docSrc := `<stream:stream xmlns:stream="http://etherx.jabber.org/streams" xmlns="jabber:client"><a/></stream:stream>`
+	p := New(strings.NewReader(docSrc), SocketStream, 1024)
+
+	// when
+	ev0, err0 := p.NextEvent()
+	ev1, err1 := p.NextEvent()
+	ev2, err2 := p.NextEvent()
+	ev3, err3 := p.NextEvent()
+	_, err4 := p.NextEvent()
+
+	// then
+	require.Nil(t, err0)
+	require.Nil(t, err1)
+	require.Nil(t, err2)
+	require.Nil(t, err3)
+
+	require.Equal(t, StartElementEvent, ev0.Kind)
+	require.Equal(t, "stream:stream", ev0.Name)
+	require.Equal(t, EndElementEvent, ev1.Kind)
+	require.Equal(t, "stream:stream", ev1.Name)
+
+	require.Equal(t, StartElementEvent, ev2.Kind)
+	require.Equal(t, "a", ev2.Name)
+	require.Equal(t, 0, ev2.Depth)
+	require.Equal(t, EndElementEvent, ev3.Kind)
+
+	require.Equal(t, ErrStreamClosedByPeer, err4)
+}
+
+func TestParser_NextEventErrTooLargeStanza(t *testing.T) {
+	// given
+	docSrc := `<a/><b>some text</b>`
+	p := New(strings.NewReader(docSrc), DefaultMode, 8)
+
+	// when
+	_, err0 := p.NextEvent()
+	_, err1 := p.NextEvent()
+	_, err2 := p.NextEvent()
+	_, err3 := p.NextEvent()
+
+	// then
+	require.Nil(t, err0)
+	require.Nil(t, err1)
+	require.Nil(t, err2)
+	require.Equal(t, ErrTooLargeStanza, err3)
+}
+
+func TestParser_NextEventUnexpectedEnd(t *testing.T) {
+	// given
+	p := New(strings.NewReader(`<a></b>`), DefaultMode, 1024)
+
+	// when
+	_, err0 := p.NextEvent()
+	_, err1 := p.NextEvent()
+
+	// then
+	require.Nil(t, err0)
+	require.NotNil(t, err1)
+}
+
+func TestTreeBuilder_Push(t *testing.T) {
+	// given
+	docSrc := `<iq id='1' type='get'><query xmlns='jabber:iq:roster'><item jid='noelia@jackal.im'>friend</item></query></iq>`
+	p := New(strings.NewReader(docSrc), DefaultMode, 1024)
+	tb := NewTreeBuilder()
+
+	// when
+	var elem stravaganza.Element
+	for elem == nil {
+		ev, err := p.NextEvent()
+		require.Nil(t, err)
+
+		elem, err = tb.Push(ev)
+		require.Nil(t, err)
+	}
+
+	// then
+	require.Equal(t, docSrc, elem.String())
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppparser
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/jackal-xmpp/stravaganza"
+)
+
+// ErrTooLargeFrame will be returned by ParseFrame when the size of the incoming frame is too large.
+var ErrTooLargeFrame = errors.New("xmppparser: too large frame")
+
+var (
+	errIncompleteFrame   = errors.New("xmppparser: incomplete frame")
+	errMultiElementFrame = errors.New("xmppparser: frame must contain exactly one element")
+)
+
+// ParseFrame parses a WebSocket frame, which must contain exactly one complete XML element (RFC 7395 section 3.3.3).
+// A maxFrameSize value of zero means no frame size limit.
+// ErrStreamClosedByPeer is returned whenever frame contains a <close/> framing element, along with
+// the parsed element itself in order to let the caller inspect its attributes (e.g. 'see-other-uri').
+func ParseFrame(frame []byte, maxFrameSize int, opts ...Option) (stravaganza.Element, error) {
+	if maxFrameSize > 0 && len(frame) > maxFrameSize {
+		return nil, ErrTooLargeFrame
+	}
+	// framing elements are told apart once parsed, in order to keep <close/> element attributes
+	p := New(bytes.NewReader(frame), DefaultMode, 0, opts...)
+	el, err := p.Parse()
+	switch {
+	case errors.Is(err, io.EOF):
+		return nil, errIncompleteFrame
+	case err != nil:
+		return nil, err
+	}
+	if len(bytes.TrimSpace(frame[p.dec.InputOffset():])) > 0 {
+		return nil, errMultiElementFrame
+	}
+	if stravaganza.LocalName(el) == "close" && stravaganza.NamespaceURI(el) == framingNamespace {
+		return el, ErrStreamClosedByPeer
+	}
+	return el, nil
+}
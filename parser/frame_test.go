@@ -0,0 +1,133 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppparser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParser_WebSocketFraming(t *testing.T) {
+	// given
+	docSrc := `<open xmlns="urn:ietf:params:xml:ns:xmpp-framing" to="example.com" version="1.0"/>` +
+		`<iq xmlns="jabber:client" id="1" type="get"><ping xmlns="urn:xmpp:ping"/></iq>` +
+		`<close xmlns="urn:ietf:params:xml:ns:xmpp-framing"/>`
+	p := New(strings.NewReader(docSrc), WebSocketFraming, 1024)
+
+	// when
+	open, err1 := p.Parse()
+	iq, err2 := p.Parse()
+	_, err3 := p.Parse()
+
+	// then
+	require.Nil(t, err1)
+	require.Nil(t, err2)
+	require.Equal(t, ErrStreamClosedByPeer, err3)
+	require.Equal(t, "open", open.Name())
+	require.Equal(t, "example.com", open.Attribute("to"))
+	require.Equal(t, "iq", iq.Name())
+}
+
+func TestParser_ParseFrame(t *testing.T) {
+	// given
+	frame := []byte(`<message xmlns="jabber:client" to="juliet@example.com"><body>Hi</body></message>` + "\n")
+
+	// when
+	el, err := ParseFrame(frame, 1024, WithNamespaceResolution())
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, "message", el.Name())
+	require.Equal(t, "jabber:client", stravaganza.NamespaceURI(el))
+	require.Equal(t, "Hi", el.Child("body").Text())
+}
+
+func TestParser_ParseFrameClose(t *testing.T) {
+	// given
+	frame := []byte(`<close xmlns="urn:ietf:params:xml:ns:xmpp-framing" see-other-uri="wss://otherendpoint.example/xmpp-bind"/>`)
+
+	// when
+	el, err := ParseFrame(frame, 0)
+
+	// then
+	require.Equal(t, ErrStreamClosedByPeer, err)
+	require.NotNil(t, el)
+	require.Equal(t, "wss://otherendpoint.example/xmpp-bind", el.Attribute("see-other-uri"))
+}
+
+func TestParser_ParseFramePrefixedClose(t *testing.T) {
+	// given
+	frame := []byte(`<f:close xmlns:f="urn:ietf:params:xml:ns:xmpp-framing"/>`)
+	p := New(strings.NewReader(string(frame)), WebSocketFraming, 1024)
+
+	// when
+	el, err1 := ParseFrame(frame, 0)
+	_, err2 := p.Parse()
+
+	// then
+	require.Equal(t, ErrStreamClosedByPeer, err1)
+	require.Equal(t, "f:close", el.Name())
+	require.Equal(t, ErrStreamClosedByPeer, err2)
+}
+
+func TestParser_ParseFrameNotClose(t *testing.T) {
+	var tcs = []string{
+		`<close/>`,
+		`<close xmlns="urn:example:other"/>`,
+		`<f:close xmlns:f="urn:example:other" xmlns="urn:ietf:params:xml:ns:xmpp-framing"/>`,
+	}
+	for _, frame := range tcs {
+		p := New(strings.NewReader(frame), WebSocketFraming, 1024)
+
+		el, err1 := ParseFrame([]byte(frame), 0)
+		_, err2 := p.Parse()
+
+		require.Nil(t, err1, frame)
+		require.NotNil(t, el, frame)
+		require.Nil(t, err2, frame)
+	}
+}
+
+func TestParser_ParseFrameErrors(t *testing.T) {
+	var tcs = []struct {
+		name  string
+		frame string
+		err   error
+	}{
+		{name: "TooLarge", frame: `<message><body>` + strings.Repeat("a", 64) + `</body></message>`, err: ErrTooLargeFrame},
+		{name: "Empty", frame: ``, err: errIncompleteFrame},
+		{name: "Incomplete", frame: `<message><body>Hi</body>`, err: errIncompleteFrame},
+		{name: "MultipleElements", frame: `<r xmlns="urn:xmpp:sm:3"/><r xmlns="urn:xmpp:sm:3"/>`, err: errMultiElementFrame},
+		{name: "TrailingText", frame: `<r xmlns="urn:xmpp:sm:3"/> text`, err: errMultiElementFrame},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseFrame([]byte(tc.frame), 64)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestParser_CloseOutsideFraming(t *testing.T) {
+	p := New(strings.NewReader(`<close xmlns="urn:ietf:params:xml:ns:xmpp-framing"/>`), SocketStream, 1024)
+
+	el, err := p.Parse()
+
+	require.Nil(t, err)
+	require.Equal(t, "close", el.Name())
+}
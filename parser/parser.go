@@ -23,10 +23,15 @@ import (
 	"github.com/jackal-xmpp/stravaganza"
 )
 
-const rootElementIndex = -1
-
 const (
 	streamName = "stream"
+
+	framingNamespace = "urn:ietf:params:xml:ns:xmpp-framing"
+
+	xmlnsPrefix = "xmlns"
+
+	xmlPrefix          = "xml"
+	xmlPrefixNamespace = "http://www.w3.org/XML/1998/namespace"
 )
 
 // ParsingMode defines the way in which special parsed element
@@ -39,6 +44,10 @@ const (
 
 	// SocketStream treats incoming elements as provided from a socket transport.
 	SocketStream
+
+	// WebSocketFraming treats incoming elements as provided from a WebSocket transport (RFC 7395),
+	// where stream boundaries are delimited by <open/> and <close/> framing elements.
+	WebSocketFraming
 )
 
 // ErrTooLargeStanza will be returned Parse when the size of the incoming stanza is too large.
@@ -47,123 +56,229 @@ var ErrTooLargeStanza = errors.New("parser: too large stanza")
 // ErrStreamClosedByPeer will be returned by Parse when stream closed element is parsed.
 var ErrStreamClosedByPeer = errors.New("parser: stream closed by peer")
 
+// Option defines a Parser configuration option.
+type Option func(p *Parser)
+
+// WithNamespaceResolution enables namespace aware parsing.
+// When enabled every parsed element namespace URI gets resolved according to its ancestors
+// and its own namespace declarations. Referencing an undeclared prefix is reported as an error.
+func WithNamespaceResolution() Option {
+	return func(p *Parser) {
+		p.resolveNamespaces = true
+	}
+}
+
+type nsBinding struct {
+	prefix, uri string
+}
+
 // Parser parses arbitrary XML input and builds an array with the structure of all tag and data elements.
+//
+// Input can be consumed either as fully built elements, by means of Parse, or as a stream
+// of parsing events by means of NextEvent. Both methods should not be mixed on the same Parser instance.
 type Parser struct {
 	dec           *xml.Decoder
 	mode          ParsingMode
-	nextElement   stravaganza.Element
-	stack         []*stravaganza.Builder
-	pIndex        int
-	inElement     bool
+	tb            *TreeBuilder
+	names         []string
+	pendingEvent  *Event
 	lastOffset    int64
 	maxStanzaSize int64
+
+	resolveNamespaces bool
+	nsBindings        []nsBinding
+	nsMarks           []int
 }
 
 // New creates an empty Parser instance.
-func New(reader io.Reader, mode ParsingMode, maxStanzaSize int) *Parser {
-	return &Parser{
+func New(reader io.Reader, mode ParsingMode, maxStanzaSize int, opts ...Option) *Parser {
+	p := &Parser{
 		mode:          mode,
 		dec:           xml.NewDecoder(reader),
-		pIndex:        rootElementIndex,
+		tb:            NewTreeBuilder(),
 		maxStanzaSize: int64(maxStanzaSize),
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 // Parse parses next available XML element from reader.
 func (p *Parser) Parse() (stravaganza.Element, error) {
-	t, err := p.dec.RawToken()
-	if err != nil {
-		return nil, err
+	for {
+		ev, err := p.NextEvent()
+		if err != nil {
+			return nil, err
+		}
+		elem, err := p.tb.Push(ev)
+		if err != nil {
+			return nil, err
+		}
+		if elem != nil {
+			return elem, nil
+		}
+	}
+}
+
+// NextEvent parses next available event from reader.
+//
+// In SocketStream mode the stream opening tag is reported as a StartElementEvent immediately
+// followed by its matching EndElementEvent, in the same way Parse returns it as an empty element.
+func (p *Parser) NextEvent() (Event, error) {
+	if p.pendingEvent != nil {
+		ev := *p.pendingEvent
+		p.pendingEvent = nil
+		return ev, nil
 	}
 	for {
+		startOff := p.dec.InputOffset()
+		t, err := p.dec.RawToken()
+		if err != nil {
+			return Event{}, err
+		}
 		// check max stanza size limit
 		off := p.dec.InputOffset()
 		if p.maxStanzaSize > 0 && off-p.lastOffset > p.maxStanzaSize {
-			return nil, ErrTooLargeStanza
+			return Event{}, ErrTooLargeStanza
 		}
 		switch t1 := t.(type) {
 		case xml.StartElement:
-			p.startElement(t1)
-			if p.mode == SocketStream && t1.Name.Local == streamName && t1.Name.Space == streamName {
-				if err := p.closeElement(xmlName(t1.Name.Space, t1.Name.Local)); err != nil {
-					return nil, err
+			if p.mode == WebSocketFraming && len(p.names) == 0 && isFramingClose(t1) {
+				return Event{}, ErrStreamClosedByPeer
+			}
+			ev := Event{
+				Kind:        StartElementEvent,
+				Name:        xmlName(t1.Name.Space, t1.Name.Local),
+				Attributes:  attributes(t1.Attr),
+				Depth:       len(p.names),
+				StartOffset: startOff,
+				EndOffset:   off,
+			}
+			isStreamOpen := p.mode == SocketStream && t1.Name.Local == streamName && t1.Name.Space == streamName
+			if p.resolveNamespaces {
+				if isStreamOpen {
+					// stream namespace declarations remain in scope for all stream elements
+					p.nsBindings = p.nsBindings[:0]
+					p.nsMarks = p.nsMarks[:0]
+				} else {
+					p.nsMarks = append(p.nsMarks, len(p.nsBindings))
+				}
+				ns, err := p.resolveNamespace(t1)
+				if err != nil {
+					return Event{}, err
 				}
-				goto done
+				ev.Namespace = ns
 			}
+			if isStreamOpen {
+				p.pendingEvent = &Event{
+					Kind:        EndElementEvent,
+					Name:        ev.Name,
+					Namespace:   ev.Namespace,
+					Depth:       ev.Depth,
+					StartOffset: off,
+					EndOffset:   off,
+				}
+				p.lastOffset = off
+				return ev, nil
+			}
+			p.names = append(p.names, ev.Name)
+			return ev, nil
 
 		case xml.CharData:
-			if p.inElement {
-				p.setElementText(t1)
+			if len(p.names) == 0 {
+				continue
 			}
+			return Event{
+				Kind:        CharDataEvent,
+				Text:        string(t1),
+				Depth:       len(p.names),
+				StartOffset: startOff,
+				EndOffset:   off,
+			}, nil
 
 		case xml.EndElement:
 			if p.mode == SocketStream && t1.Name.Local == streamName && t1.Name.Space == streamName {
-				return nil, ErrStreamClosedByPeer
+				return Event{}, ErrStreamClosedByPeer
 			}
-			if err := p.endElement(t1); err != nil {
-				return nil, err
+			name := xmlName(t1.Name.Space, t1.Name.Local)
+			if len(p.names) == 0 || p.names[len(p.names)-1] != name {
+				return Event{}, errUnexpectedEnd(name)
 			}
-			if p.pIndex == rootElementIndex {
-				goto done
+			p.names = p.names[:len(p.names)-1]
+			if len(p.names) == 0 {
+				p.lastOffset = off
 			}
-		}
-		t, err = p.dec.RawToken()
-		if err != nil {
-			return nil, err
+			var ns string
+			if p.resolveNamespaces {
+				ns = p.lookupNamespace(t1.Name.Space)
+				mark := p.nsMarks[len(p.nsMarks)-1]
+				p.nsBindings = p.nsBindings[:mark]
+				p.nsMarks = p.nsMarks[:len(p.nsMarks)-1]
+			}
+			return Event{
+				Kind:        EndElementEvent,
+				Name:        name,
+				Namespace:   ns,
+				Depth:       len(p.names),
+				StartOffset: startOff,
+				EndOffset:   off,
+			}, nil
 		}
 	}
-
-done:
-	p.lastOffset = p.dec.InputOffset()
-	elem := p.nextElement
-	p.nextElement = nil
-
-	return elem, nil
 }
 
-func (p *Parser) startElement(t xml.StartElement) {
-	name := xmlName(t.Name.Space, t.Name.Local)
-
-	attrs := make([]stravaganza.Attribute, 0, len(t.Attr))
+func (p *Parser) resolveNamespace(t xml.StartElement) (string, error) {
 	for _, a := range t.Attr {
-		name := xmlName(a.Name.Space, a.Name.Local)
-		attrs = append(attrs, stravaganza.Attribute{Label: name, Value: a.Value})
+		switch {
+		case a.Name.Space == "" && a.Name.Local == xmlnsPrefix:
+			p.nsBindings = append(p.nsBindings, nsBinding{uri: a.Value})
+		case a.Name.Space == xmlnsPrefix:
+			p.nsBindings = append(p.nsBindings, nsBinding{prefix: a.Name.Local, uri: a.Value})
+		}
 	}
-	builder := stravaganza.NewBuilder(name).WithAttributes(attrs...)
-	p.stack = append(p.stack, builder)
-
-	p.pIndex = len(p.stack) - 1
-	p.inElement = true
-}
-
-func (p *Parser) setElementText(t xml.CharData) {
-	p.stack[p.pIndex] = p.stack[p.pIndex].WithText(string(t))
+	prefix := t.Name.Space
+	ns := p.lookupNamespace(prefix)
+	if len(ns) == 0 && len(prefix) > 0 {
+		return "", fmt.Errorf("xmppparser: unbound namespace prefix %q", prefix)
+	}
+	return ns, nil
 }
 
-func (p *Parser) endElement(t xml.EndElement) error {
-	return p.closeElement(xmlName(t.Name.Space, t.Name.Local))
+func (p *Parser) lookupNamespace(prefix string) string {
+	if prefix == xmlPrefix {
+		return xmlPrefixNamespace
+	}
+	for i := len(p.nsBindings) - 1; i >= 0; i-- {
+		if p.nsBindings[i].prefix == prefix {
+			return p.nsBindings[i].uri
+		}
+	}
+	return ""
 }
 
-func (p *Parser) closeElement(name string) error {
-	if p.pIndex == rootElementIndex {
-		return errUnexpectedEnd(name)
+// isFramingClose tells whether t is a RFC 7395 <close/> framing element.
+// Given that framing elements are top level ones, only t own namespace declarations are considered.
+func isFramingClose(t xml.StartElement) bool {
+	if t.Name.Local != "close" {
+		return false
 	}
-	builder := p.stack[p.pIndex]
-	p.stack = p.stack[:p.pIndex]
-
-	element := builder.Build()
-
-	if name != element.Name() {
-		return errUnexpectedEnd(name)
+	for _, a := range t.Attr {
+		isDefaultDecl := a.Name.Space == "" && a.Name.Local == xmlnsPrefix
+		isPrefixDecl := a.Name.Space == xmlnsPrefix && a.Name.Local == t.Name.Space
+		if (len(t.Name.Space) == 0 && isDefaultDecl) || (len(t.Name.Space) > 0 && isPrefixDecl) {
+			return a.Value == framingNamespace
+		}
 	}
-	p.pIndex = len(p.stack) - 1
-	if p.pIndex == rootElementIndex {
-		p.nextElement = element
-	} else {
-		p.stack[p.pIndex] = p.stack[p.pIndex].WithChild(element)
+	return false
+}
+
+func attributes(xmlAttrs []xml.Attr) []stravaganza.Attribute {
+	attrs := make([]stravaganza.Attribute, 0, len(xmlAttrs))
+	for _, a := range xmlAttrs {
+		attrs = append(attrs, stravaganza.Attribute{Label: xmlName(a.Name.Space, a.Name.Local), Value: a.Value})
 	}
-	p.inElement = false
-	return nil
+	return attrs
 }
 
 func xmlName(space, local string) string {
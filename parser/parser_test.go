@@ -18,6 +18,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/jackal-xmpp/stravaganza"
 	"github.com/stretchr/testify/require"
 )
 
@@ -81,6 +82,55 @@ func TestParser_DocChildElements(t *testing.T) {
 	require.Equal(t, "c", childs[2].Name())
 }
 
+func TestParser_MixedContent(t *testing.T) {
+	// given
+	docSrc := `<body xmlns='http://www.w3.org/1999/xhtml'>hello <b>bold</b> world</body>`
+	p := New(strings.NewReader(docSrc), DefaultMode, 1024)
+
+	// when
+	elem, err := p.Parse()
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, "hello  world", elem.Text())
+	require.Equal(t, docSrc, elem.String())
+
+	nodes := stravaganza.Nodes(elem)
+	require.Len(t, nodes, 3)
+	require.Equal(t, "hello ", nodes[0].Text)
+	require.Equal(t, "b", nodes[1].Element.Name())
+	require.Equal(t, " world", nodes[2].Text)
+}
+
+func TestParser_InterElementWhitespace(t *testing.T) {
+	// given
+	docSrc := "<message>\n  <body>hi</body>\n  <thread>t1</thread>\n</message>"
+	p := New(strings.NewReader(docSrc), DefaultMode, 1024)
+
+	// when
+	elem, err := p.Parse()
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, "\n  \n  \n", elem.Text())
+	require.Equal(t, docSrc, elem.String())
+	require.Len(t, stravaganza.Nodes(elem), 5)
+}
+
+func TestParser_WhitespaceBetweenChildren(t *testing.T) {
+	// given
+	docSrc := `<body xmlns='http://www.w3.org/1999/xhtml'><b>a</b> <i>b</i></body>`
+	p := New(strings.NewReader(docSrc), DefaultMode, 1024)
+
+	// when
+	elem, err := p.Parse()
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, " ", elem.Text())
+	require.Equal(t, docSrc, elem.String())
+}
+
 func TestParser_Stream(t *testing.T) {
 	openStreamXML := `<stream:stream xmlns:stream="http://etherx.jabber.org/streams" version="1.0" xmlns="jabber:client" to="localhost" xml:lang="en" xmlns:xml="http://www.w3.org/XML/1998/namespace"> `
 	p := New(strings.NewReader(openStreamXML), SocketStream, 1024)
@@ -96,6 +146,54 @@ func TestParser_Stream(t *testing.T) {
 	require.Equal(t, ErrStreamClosedByPeer, err)
 }
 
+func TestParser_NamespaceResolution(t *testing.T) {
+	// given
+	docSrc := `<stream:stream xmlns:stream="http://etherx.jabber.org/streams" xmlns="jabber:server" version="1.0">` +
+		`<stream:features/>` +
+		`<iq id="1" type="get"><x:ping xmlns:x="urn:xmpp:ping"/></iq>` +
+		`<iq id="2" type="get"><ping xmlns="urn:xmpp:ping"/></iq>`
+	p := New(strings.NewReader(docSrc), SocketStream, 1024, WithNamespaceResolution())
+
+	// when
+	stream, err0 := p.Parse()
+	features, err1 := p.Parse()
+	iq1, err2 := p.Parse()
+	iq2, err3 := p.Parse()
+
+	// then
+	require.Nil(t, err0)
+	require.Nil(t, err1)
+	require.Nil(t, err2)
+	require.Nil(t, err3)
+
+	require.Equal(t, "http://etherx.jabber.org/streams", stravaganza.NamespaceURI(stream))
+
+	require.Equal(t, "features", stravaganza.LocalName(features))
+	require.Equal(t, "http://etherx.jabber.org/streams", stravaganza.NamespaceURI(features))
+
+	require.Equal(t, "jabber:server", stravaganza.NamespaceURI(iq1))
+	require.Equal(t, "jabber:server", stravaganza.NamespaceURI(iq2))
+
+	ping1 := iq1.ChildNamespace("ping", "urn:xmpp:ping")
+	ping2 := iq2.ChildNamespace("ping", "urn:xmpp:ping")
+	require.NotNil(t, ping1)
+	require.NotNil(t, ping2)
+	require.Equal(t, "x:ping", ping1.Name())
+	require.Equal(t, "ping", ping2.Name())
+}
+
+func TestParser_NamespaceResolutionUnboundPrefix(t *testing.T) {
+	// given
+	p := New(strings.NewReader(`<a><x:b/></a>`), DefaultMode, 1024, WithNamespaceResolution())
+
+	// when
+	elem, err := p.Parse()
+
+	// then
+	require.Nil(t, elem)
+	require.NotNil(t, err)
+}
+
 func BenchmarkParser_Parse(b *testing.B) {
 	docSrc := "<iq id='config1' type='result' from='pubsub.shakespeare.lit' to='hamlet@denmark.lit/elsinore'><pubsub xmlns='http://jabber.org/protocol/pubsub#owner'><configure node='princely_musings'><x xmlns='jabber:x:data' type='form'><field type='hidden' var='FORM_TYPE'><value>http://jabber.org/protocol/pubsub#node_config</value></field><field type='text-single' label='The default language of the node' var='pubsub#language'/><field type='text-single' label='A friendly name for the node' var='pubsub#title'/><field type='text-single' label='A description of the node' var='pubsub#description'/><field type='boolean' label='Whether to deliver payloads with event notifications' var='pubsub#deliver_payloads'><value>false</value></field><field type='boolean' label='Whether to deliver event notifications' var='pubsub#deliver_notifications'><value>false</value></field><field type='boolean' label='Whether to notify subscribers when the node configuration changes' var='pubsub#notify_config'><value>false</value></field><field type='boolean' label='Whether to notify subscribers when the node is deleted' var='pubsub#notify_delete'><value>false</value></field><field type='boolean' label='Whether to notify subscribers when items are removed from the node' var='pubsub#notify_retract'><value>false</value></field><field type='boolean' label='Whether to notify owners about new subscribers and unsubscribes' var='pubsub#notify_sub'><value>false</value></field><field type='boolean' label='Whether to persist items to storage' var='pubsub#persist_items'><value>false</value></field><field type='text-single' label='The maximum number of items to persist. `max` for no specific limit other than a server imposed maximum.' var='pubsub#max_items'><value>120</value></field><field type='text-single' label='Number of seconds after which to automatically purge items. `max` for no specific limit other than a server imposed maximum.' var='pubsub#item_expire'/><field type='boolean' label='Whether to allow subscriptions' var='pubsub#subscribe'><value>false</value></field><field type='list-single' label='Who may subscribe and retrieve items' var='pubsub#access_model'><value>open</value></field><field type='list-multi' label='The roster group(s) allowed to subscribe and retrieve items' var='pubsub#roster_groups_allowed'/><field type='list-single' label='The publisher model' var='pubsub#publish_model'><value/></field><field type='boolean' label='Whether to purge all items when the relevant publisher goes offline' var='pubsub#purge_offline'><value>false</value></field><field type='text-single' label='The maximum payload size in bytes' var='pubsub#max_payload_size'><value>65536</value></field><field type='list-single' label='When to send the last published item' var='pubsub#send_last_published_item'><value/></field><field type='boolean' label='Whether to deliver notifications to available users only' var='pubsub#presence_based_delivery'><value>false</value></field><field type='list-single' label='Specify the delivery style for notifications' var='pubsub#notification_type'><value/></field><field type='text-single' label='The semantic type information of data in the node, usually specified by the namespace of the payload (if any)' var='pubsub#type'/><field type='text-single' label='The URL of an XSL transformation which can be applied to payloads in order to generate an appropriate message body element.' var='pubsub#body_xslt'/><field type='text-single' label='The URL of an XSL transformation which can be applied to the payload format in order to generate a valid Data Forms result that the client could display using a generic Data Forms rendering engine' var='pubsub#dataform_xslt'/></x></configure></pubsub></iq>"
 
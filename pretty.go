@@ -0,0 +1,229 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stravaganza
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	redactedText = "[redacted]"
+	elidedText   = "…"
+
+	saslNamespace  = "urn:ietf:params:xml:ns:xmpp-sasl"
+	sasl2Namespace = "urn:xmpp:sasl:2"
+)
+
+// RedactionRule identifies those elements whose content must be redacted.
+type RedactionRule struct {
+	// Name is the element local name.
+	Name string
+
+	// Namespace is the element namespace URI. An empty value matches any namespace.
+	Namespace string
+}
+
+// DefaultRedactionRules contains the redaction rules applied by default,
+// covering message bodies, passwords and SASL and SASL2 payloads.
+var DefaultRedactionRules = []RedactionRule{
+	{Name: "body"},
+	{Name: "password"},
+	{Name: "auth", Namespace: saslNamespace},
+	{Name: "response", Namespace: saslNamespace},
+	{Name: "challenge", Namespace: saslNamespace},
+	{Name: "success", Namespace: saslNamespace},
+	{Name: "initial-response", Namespace: sasl2Namespace},
+	{Name: "response", Namespace: sasl2Namespace},
+	{Name: "challenge", Namespace: sasl2Namespace},
+	{Name: "additional-data", Namespace: sasl2Namespace},
+}
+
+// DefaultPrettySerializer is the serializer used to format elements with the %+v verb.
+var DefaultPrettySerializer = &PrettySerializer{
+	Indent:         "  ",
+	MaxTextLength:  256,
+	RedactionRules: DefaultRedactionRules,
+}
+
+// PrettySerializer serializes elements into an indented and redacted XML representation.
+// Its output is meant to be human readable and safe to log, not to be parsed back.
+type PrettySerializer struct {
+	// Indent is the string used to indent each nesting level.
+	// If empty, the whole element is written in a single line.
+	Indent string
+
+	// MaxDepth is the maximum number of nested element levels to be serialized.
+	// Content of deeper elements is elided. Zero means no limit.
+	MaxDepth int
+
+	// MaxTextLength is the maximum number of characters serialized per text node.
+	// Longer text nodes are truncated. Zero means no limit.
+	MaxTextLength int
+
+	// RedactionRules identifies those elements whose content will be redacted.
+	RedactionRules []RedactionRule
+}
+
+// Serialize writes el pretty representation to w.
+func (s *PrettySerializer) Serialize(w io.Writer, el Element) error {
+	return s.writeElement(w, el.Proto(), "", 0, false)
+}
+
+// String returns el pretty representation.
+func (s *PrettySerializer) String(el Element) string {
+	buf := bytes.NewBuffer(nil)
+	_ = s.Serialize(buf, el)
+	return buf.String()
+}
+
+// writeElement writes pb pretty representation at depth nesting level.
+// Inline elements, such as those contained into mixed content, are written without indentation.
+func (s *PrettySerializer) writeElement(w io.Writer, pb *PBElement, parentNS string, depth int, inline bool) error {
+	ns := parentNS
+	if uri := getProtoElementNamespaceURI(pb); len(uri) > 0 {
+		ns = uri
+	}
+	if depth > 0 && !inline {
+		if err := s.writeIndent(w, depth); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(w, "<"+pb.GetName()); err != nil {
+		return err
+	}
+	for _, attr := range pb.GetAttributes() {
+		if len(attr.Value) == 0 {
+			continue
+		}
+		if _, err := io.WriteString(w, " "+attr.Label+"='"); err != nil {
+			return err
+		}
+		if err := escapeAttrValue(w, s.truncate(attr.Value)); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "'"); err != nil {
+			return err
+		}
+	}
+	hasChildren := len(pb.GetElements()) > 0
+	if !hasChildren && len(pb.GetText()) == 0 {
+		_, err := io.WriteString(w, "/>")
+		return err
+	}
+	if _, err := io.WriteString(w, ">"); err != nil {
+		return err
+	}
+	switch {
+	case s.isRedacted(pb, ns):
+		if _, err := io.WriteString(w, redactedText); err != nil {
+			return err
+		}
+
+	case s.MaxDepth > 0 && depth+1 >= s.MaxDepth && hasChildren:
+		if _, err := io.WriteString(w, elidedText); err != nil {
+			return err
+		}
+
+	case !hasChildren:
+		if err := escapeText(w, s.truncate(pb.GetText()), false); err != nil {
+			return err
+		}
+
+	case len(pb.GetText()) > 0:
+		// mixed content is written inline, given that indentation would alter it
+		for _, node := range (&element{pb: pb}).Nodes() {
+			if node.IsText() {
+				if err := escapeText(w, s.truncate(node.Text), false); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := s.writeElement(w, node.Element.Proto(), ns, depth+1, true); err != nil {
+				return err
+			}
+		}
+
+	default:
+		for _, child := range pb.GetElements() {
+			if err := s.writeElement(w, child, ns, depth+1, inline); err != nil {
+				return err
+			}
+		}
+		if !inline {
+			if err := s.writeIndent(w, depth); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := io.WriteString(w, "</"+pb.GetName()+">")
+	return err
+}
+
+// writeIndent writes a new line followed by depth level indentation.
+func (s *PrettySerializer) writeIndent(w io.Writer, depth int) error {
+	if len(s.Indent) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(w, "\n"+strings.Repeat(s.Indent, depth))
+	return err
+}
+
+func (s *PrettySerializer) isRedacted(pb *PBElement, ns string) bool {
+	_, local := splitName(pb.GetName())
+	for _, rule := range s.RedactionRules {
+		if rule.Name == local && (len(rule.Namespace) == 0 || rule.Namespace == ns) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *PrettySerializer) truncate(text string) string {
+	if s.MaxTextLength <= 0 || utf8.RuneCountInString(text) <= s.MaxTextLength {
+		return text
+	}
+	var n int
+	for i := range text {
+		if n == s.MaxTextLength {
+			return text[:i] + elidedText
+		}
+		n++
+	}
+	return text
+}
+
+func (e *element) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case f.Flag('+'):
+			_ = DefaultPrettySerializer.Serialize(f, e)
+		case f.Flag('#'):
+			_, _ = io.WriteString(f, e.GoString())
+		default:
+			_, _ = io.WriteString(f, e.String())
+		}
+	case 's':
+		_, _ = io.WriteString(f, e.String())
+	case 'q':
+		_, _ = fmt.Fprintf(f, "%q", e.String())
+	default:
+		_, _ = fmt.Fprintf(f, "%%!%c(stravaganza.Element=%s)", verb, e.String())
+	}
+}
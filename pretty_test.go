@@ -0,0 +1,171 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stravaganza
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrettySerializer_Serialize(t *testing.T) {
+	// given
+	el := NewBuilder("iq").
+		WithAttribute("type", "set").
+		WithAttribute("id", "reg2").
+		WithChild(
+			NewBuilder("query").
+				WithAttribute(xmlNamespace, "jabber:iq:register").
+				WithChild(NewBuilder("username").WithText("bill").Build()).
+				WithChild(NewBuilder("password").WithText("Calliope").Build()).
+				WithChild(NewBuilder("email").Build()).
+				Build(),
+		).
+		Build()
+
+	// when
+	out := DefaultPrettySerializer.String(el)
+
+	// then
+	expected := `<iq type='set' id='reg2'>
+  <query xmlns='jabber:iq:register'>
+    <username>bill</username>
+    <password>[redacted]</password>
+    <email/>
+  </query>
+</iq>`
+	require.Equal(t, expected, out)
+}
+
+func TestPrettySerializer_RedactionNamespace(t *testing.T) {
+	// given
+	auth := NewBuilder("auth").
+		WithAttribute(xmlNamespace, saslNamespace).
+		WithAttribute("mechanism", "PLAIN").
+		WithText("AGp1bGlldAByMG0zMG15cjBtMzA=").
+		Build()
+	nonSASLAuth := NewBuilder("auth").
+		WithText("visible").
+		Build()
+
+	// when
+	out1 := DefaultPrettySerializer.String(auth)
+	out2 := DefaultPrettySerializer.String(nonSASLAuth)
+
+	// then
+	require.Equal(t, "<auth xmlns='urn:ietf:params:xml:ns:xmpp-sasl' mechanism='PLAIN'>[redacted]</auth>", out1)
+	require.Equal(t, "<auth>visible</auth>", out2)
+}
+
+func TestPrettySerializer_RedactionSASL2(t *testing.T) {
+	// given
+	authenticate := NewBuilder("authenticate").
+		WithAttribute(xmlNamespace, sasl2Namespace).
+		WithAttribute("mechanism", "PLAIN").
+		WithChild(NewBuilder("initial-response").WithText("AGp1bGlldAByMG0zMG15cjBtMzA=").Build()).
+		Build()
+	success := NewBuilder("success").
+		WithAttribute(xmlNamespace, sasl2Namespace).
+		WithChild(NewBuilder("additional-data").WithText("dj1hYmM=").Build()).
+		WithChild(NewBuilder("authorization-identifier").WithText("juliet@capulet.lit").Build()).
+		Build()
+	response := NewBuilder("response").
+		WithAttribute(xmlNamespace, sasl2Namespace).
+		WithText("Yz1iaXdz").
+		Build()
+	challenge := NewBuilder("challenge").
+		WithAttribute(xmlNamespace, sasl2Namespace).
+		WithText("cj1meWtv").
+		Build()
+
+	// when
+	out1 := DefaultPrettySerializer.String(authenticate)
+	out2 := DefaultPrettySerializer.String(success)
+	out3 := DefaultPrettySerializer.String(response)
+	out4 := DefaultPrettySerializer.String(challenge)
+
+	// then
+	require.Equal(t, "<authenticate xmlns='urn:xmpp:sasl:2' mechanism='PLAIN'>\n"+
+		"  <initial-response>[redacted]</initial-response>\n"+
+		"</authenticate>", out1)
+	require.Equal(t, "<success xmlns='urn:xmpp:sasl:2'>\n"+
+		"  <additional-data>[redacted]</additional-data>\n"+
+		"  <authorization-identifier>juliet@capulet.lit</authorization-identifier>\n"+
+		"</success>", out2)
+	require.Equal(t, "<response xmlns='urn:xmpp:sasl:2'>[redacted]</response>", out3)
+	require.Equal(t, "<challenge xmlns='urn:xmpp:sasl:2'>[redacted]</challenge>", out4)
+}
+
+func TestPrettySerializer_Limits(t *testing.T) {
+	// given
+	s := &PrettySerializer{MaxDepth: 2, MaxTextLength: 5}
+	el := NewBuilder("a").
+		WithChild(
+			NewBuilder("b").
+				WithChild(NewBuilder("c").WithText("deep").Build()).
+				Build(),
+		).
+		WithChild(NewBuilder("d").WithText("long text").Build()).
+		WithChild(
+			NewBuilder("p").
+				AppendText("mixed ").
+				WithChild(NewBuilder("i").WithText("content").Build()).
+				Build(),
+		).
+		Build()
+
+	// when
+	out1 := s.String(el)
+	out2 := (&PrettySerializer{MaxTextLength: 5}).String(el)
+
+	// then
+	require.Equal(t, "<a><b>…</b><d>long …</d><p>…</p></a>", out1)
+	require.Equal(t, "<a><b><c>deep</c></b><d>long …</d><p>mixed…<i>conte…</i></p></a>", out2)
+}
+
+func TestPrettySerializer_MaxDepthMixedContent(t *testing.T) {
+	// given
+	s := &PrettySerializer{Indent: "  ", MaxDepth: 2}
+	el := NewBuilder("p").
+		AppendText("x").
+		WithChild(
+			NewBuilder("a").
+				WithChild(NewBuilder("b").WithChild(NewBuilder("c").Build()).Build()).
+				Build(),
+		).
+		Build()
+
+	// when
+	out := s.String(el)
+
+	// then
+	require.Equal(t, "<p>x<a>…</a></p>", out)
+}
+
+func TestElement_Format(t *testing.T) {
+	// given
+	el := NewBuilder("message").
+		WithAttribute("id", "1234").
+		WithChild(NewBuilder("body").WithText("secret").Build()).
+		Build()
+
+	// then
+	require.Equal(t, "<message id='1234'><body>secret</body></message>", fmt.Sprintf("%v", el))
+	require.Equal(t, "<message id='1234'><body>secret</body></message>", fmt.Sprintf("%s", el))
+	require.Equal(t, "<message id='1234'><body>secret</body></message>", fmt.Sprintf("%#v", el))
+	require.Equal(t, `"<message id='1234'><body>secret</body></message>"`, fmt.Sprintf("%q", el))
+	require.Equal(t, "<message id='1234'>\n  <body>[redacted]</body>\n</message>", fmt.Sprintf("%+v", el))
+}
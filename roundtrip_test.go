@@ -0,0 +1,86 @@
+// Copyright 2020 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stravaganza_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/jackal-xmpp/stravaganza"
+	xmppparser "github.com/jackal-xmpp/stravaganza/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func FuzzElement_ToXMLRoundTrip(f *testing.F) {
+	f.Add("d'artagnan@jackal.im", "hello <b>world</b>", "a & \"b\"\tc\nd\re", " tail")
+	f.Add("'><inject xmlns='evil'/>", "&amp;", "\x00\xff", "")
+	f.Add("", "", "", "]]>")
+
+	f.Fuzz(func(t *testing.T, attrValue, text, childAttrValue, tail string) {
+		b := stravaganza.NewBuilder("message").
+			WithAttribute("id", "1234").
+			AppendText(text)
+		if len(attrValue) > 0 {
+			b.WithAttribute("from", attrValue)
+		}
+		cb := stravaganza.NewBuilder("x").WithText(tail)
+		if len(childAttrValue) > 0 {
+			cb.WithAttribute("var", childAttrValue)
+		}
+		elem := b.WithChild(cb.Build()).
+			AppendText(tail).
+			Build()
+
+		p := xmppparser.New(strings.NewReader(elem.String()), xmppparser.DefaultMode, 0)
+		parsed, err := p.Parse()
+		require.NoError(t, err)
+
+		require.Equal(t, xmlSafe(attrValue), parsed.Attribute("from"))
+		require.Equal(t, xmlSafe(text)+xmlSafe(tail), parsed.Text())
+
+		child := parsed.Child("x")
+		require.NotNil(t, child)
+		require.Equal(t, xmlSafe(childAttrValue), child.Attribute("var"))
+		require.Equal(t, xmlSafe(tail), child.Text())
+
+		require.Equal(t, elem.String(), parsed.String())
+	})
+}
+
+// xmlSafe returns s as serialized by the XML encoder, replacing
+// all characters not allowed in an XML document.
+func xmlSafe(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		r, width := utf8.DecodeRuneInString(s[i:])
+		i += width
+		if (r == utf8.RuneError && width == 1) || !isXMLChar(r) {
+			sb.WriteRune(utf8.RuneError)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func isXMLChar(r rune) bool {
+	return r == 0x09 ||
+		r == 0x0A ||
+		r == 0x0D ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
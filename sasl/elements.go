@@ -0,0 +1,505 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package xmppsasl provides typed SASL negotiation elements, as defined in RFC 6120 section 6
+// and XEP-0388 (Extensible SASL Profile), along with client side SASL mechanism implementations.
+package xmppsasl
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/jackal-xmpp/stravaganza"
+)
+
+const (
+	// Namespace is the RFC 6120 SASL namespace.
+	Namespace = "urn:ietf:params:xml:ns:xmpp-sasl"
+
+	// SASL2Namespace is the XEP-0388 (SASL2) namespace.
+	SASL2Namespace = "urn:xmpp:sasl:2"
+)
+
+// Element represents a SASL negotiation element.
+type Element interface {
+	// Element returns the XML representation of the SASL element.
+	Element() stravaganza.Element
+}
+
+// Auth represents an RFC 6120 <auth/> element.
+type Auth struct {
+	// Mechanism is the selected SASL mechanism name.
+	Mechanism string
+
+	// InitialResponse is the optional initial response.
+	// A nil value means no initial response, while an empty non-nil value
+	// represents an initial response of zero length.
+	InitialResponse []byte
+}
+
+// Element satisfies Element interface.
+func (a *Auth) Element() stravaganza.Element {
+	return stravaganza.NewBuilder("auth").
+		WithAttribute(stravaganza.Namespace, Namespace).
+		WithAttribute("mechanism", a.Mechanism).
+		WithText(encodePayload(a.InitialResponse)).
+		Build()
+}
+
+// Challenge represents a <challenge/> element.
+type Challenge struct {
+	// Payload is the challenge data.
+	Payload []byte
+
+	// SASL2 tells whether the element belongs to the SASL2 namespace.
+	SASL2 bool
+}
+
+// Element satisfies Element interface.
+func (c *Challenge) Element() stravaganza.Element {
+	return stravaganza.NewBuilder("challenge").
+		WithAttribute(stravaganza.Namespace, namespace(c.SASL2)).
+		WithText(encodePayload(c.Payload)).
+		Build()
+}
+
+// Response represents a <response/> element.
+type Response struct {
+	// Payload is the response data.
+	Payload []byte
+
+	// SASL2 tells whether the element belongs to the SASL2 namespace.
+	SASL2 bool
+}
+
+// Element satisfies Element interface.
+func (r *Response) Element() stravaganza.Element {
+	return stravaganza.NewBuilder("response").
+		WithAttribute(stravaganza.Namespace, namespace(r.SASL2)).
+		WithText(encodePayload(r.Payload)).
+		Build()
+}
+
+// Abort represents an <abort/> element.
+type Abort struct {
+	// Text is an optional descriptive text. Only applies to SASL2.
+	Text string
+
+	// SASL2 tells whether the element belongs to the SASL2 namespace.
+	SASL2 bool
+}
+
+// Element satisfies Element interface.
+func (a *Abort) Element() stravaganza.Element {
+	b := stravaganza.NewBuilder("abort").
+		WithAttribute(stravaganza.Namespace, namespace(a.SASL2))
+	if a.SASL2 && len(a.Text) > 0 {
+		b.WithChild(textElement(a.Text, ""))
+	}
+	return b.Build()
+}
+
+// Success represents a <success/> element.
+type Success struct {
+	// AdditionalData contains the additional data with success.
+	// A nil value means no additional data, while an empty non-nil value
+	// represents additional data of zero length.
+	AdditionalData []byte
+
+	// SASL2 tells whether the element belongs to the SASL2 namespace.
+	SASL2 bool
+
+	// AuthorizationIdentifier is the JID the entity has been authorized as. Only applies to SASL2.
+	AuthorizationIdentifier string
+
+	// Other contains the results of any inline feature negotiation. Only applies to SASL2.
+	Other []stravaganza.Element
+}
+
+// Element satisfies Element interface.
+func (s *Success) Element() stravaganza.Element {
+	b := stravaganza.NewBuilder("success").
+		WithAttribute(stravaganza.Namespace, namespace(s.SASL2))
+	if !s.SASL2 {
+		return b.WithText(encodePayload(s.AdditionalData)).Build()
+	}
+	if s.AdditionalData != nil {
+		b.WithChild(payloadElement("additional-data", s.AdditionalData))
+	}
+	b.WithChild(
+		stravaganza.NewBuilder("authorization-identifier").
+			WithText(s.AuthorizationIdentifier).
+			Build(),
+	)
+	b.WithChildren(s.Other...)
+	return b.Build()
+}
+
+// Authenticate represents a SASL2 <authenticate/> element.
+type Authenticate struct {
+	// Mechanism is the selected SASL mechanism name.
+	Mechanism string
+
+	// InitialResponse is the optional initial response.
+	InitialResponse []byte
+
+	// UserAgent identifies the client. It's nil if absent.
+	UserAgent *UserAgent
+
+	// Other contains any inline feature request.
+	Other []stravaganza.Element
+}
+
+// UserAgent represents a SASL2 <user-agent/> element.
+type UserAgent struct {
+	// ID is a stable client instance identifier.
+	ID string
+
+	// Software is the client software name.
+	Software string
+
+	// Device is the client device name.
+	Device string
+}
+
+// Element satisfies Element interface.
+func (a *Authenticate) Element() stravaganza.Element {
+	b := stravaganza.NewBuilder("authenticate").
+		WithAttribute(stravaganza.Namespace, SASL2Namespace).
+		WithAttribute("mechanism", a.Mechanism)
+	if a.InitialResponse != nil {
+		b.WithChild(payloadElement("initial-response", a.InitialResponse))
+	}
+	if a.UserAgent != nil {
+		ub := stravaganza.NewBuilder("user-agent").
+			WithAttribute("id", a.UserAgent.ID)
+		if len(a.UserAgent.Software) > 0 {
+			ub.WithChild(stravaganza.NewBuilder("software").WithText(a.UserAgent.Software).Build())
+		}
+		if len(a.UserAgent.Device) > 0 {
+			ub.WithChild(stravaganza.NewBuilder("device").WithText(a.UserAgent.Device).Build())
+		}
+		b.WithChild(ub.Build())
+	}
+	b.WithChildren(a.Other...)
+	return b.Build()
+}
+
+// Continue represents a SASL2 <continue/> element.
+type Continue struct {
+	// AdditionalData contains the additional data with success.
+	AdditionalData []byte
+
+	// Tasks contains the names of the tasks the client must complete.
+	Tasks []string
+
+	// Text is an optional descriptive text.
+	Text string
+}
+
+// Element satisfies Element interface.
+func (c *Continue) Element() stravaganza.Element {
+	b := stravaganza.NewBuilder("continue").
+		WithAttribute(stravaganza.Namespace, SASL2Namespace)
+	if c.AdditionalData != nil {
+		b.WithChild(payloadElement("additional-data", c.AdditionalData))
+	}
+	tb := stravaganza.NewBuilder("tasks")
+	for _, task := range c.Tasks {
+		tb.WithChild(stravaganza.NewBuilder("task").WithText(task).Build())
+	}
+	b.WithChild(tb.Build())
+	if len(c.Text) > 0 {
+		b.WithChild(textElement(c.Text, ""))
+	}
+	return b.Build()
+}
+
+// Authentication represents the SASL2 <authentication/> stream feature.
+type Authentication struct {
+	// Mechanisms contains the offered SASL mechanisms.
+	Mechanisms []string
+
+	// Inline contains the features that can be negotiated along with authentication.
+	Inline []stravaganza.Element
+}
+
+// Element satisfies Element interface.
+func (a *Authentication) Element() stravaganza.Element {
+	b := stravaganza.NewBuilder("authentication").
+		WithAttribute(stravaganza.Namespace, SASL2Namespace)
+	for _, mechanism := range a.Mechanisms {
+		b.WithChild(stravaganza.NewBuilder("mechanism").WithText(mechanism).Build())
+	}
+	if len(a.Inline) > 0 {
+		b.WithChild(stravaganza.NewBuilder("inline").WithChildren(a.Inline...).Build())
+	}
+	return b.Build()
+}
+
+// Parse parses a SASL negotiation element, either from the RFC 6120 or SASL2 namespace.
+// The returned error will be a *Failure value whenever the element is malformed.
+func Parse(el stravaganza.Element) (Element, error) {
+	if el == nil {
+		return nil, &Failure{Condition: MalformedRequest}
+	}
+	switch stravaganza.NamespaceURI(el) {
+	case Namespace:
+		return parseSASL(el)
+	case SASL2Namespace:
+		return parseSASL2(el)
+	default:
+		return nil, &Failure{Condition: MalformedRequest, Text: fmt.Sprintf("unexpected namespace: %s", stravaganza.NamespaceURI(el))}
+	}
+}
+
+func parseSASL(el stravaganza.Element) (Element, error) {
+	switch stravaganza.LocalName(el) {
+	case "auth":
+		mechanism := el.Attribute("mechanism")
+		if len(mechanism) == 0 {
+			return nil, &Failure{Condition: InvalidMechanism}
+		}
+		payload, err := decodePayload(el.Text())
+		if err != nil {
+			return nil, err
+		}
+		return &Auth{Mechanism: mechanism, InitialResponse: payload}, nil
+
+	case "challenge":
+		payload, err := decodePayload(el.Text())
+		if err != nil {
+			return nil, err
+		}
+		return &Challenge{Payload: payload}, nil
+
+	case "response":
+		payload, err := decodePayload(el.Text())
+		if err != nil {
+			return nil, err
+		}
+		return &Response{Payload: payload}, nil
+
+	case "success":
+		payload, err := decodePayload(el.Text())
+		if err != nil {
+			return nil, err
+		}
+		return &Success{AdditionalData: payload}, nil
+
+	case "failure":
+		return parseFailure(el, false), nil
+
+	case "abort":
+		return &Abort{}, nil
+	}
+	return nil, &Failure{Condition: MalformedRequest, Text: fmt.Sprintf("unexpected element: %s", stravaganza.LocalName(el))}
+}
+
+func parseSASL2(el stravaganza.Element) (Element, error) {
+	switch stravaganza.LocalName(el) {
+	case "authenticate":
+		a := &Authenticate{Mechanism: el.Attribute("mechanism")}
+		if len(a.Mechanism) == 0 {
+			return nil, &Failure{Condition: InvalidMechanism, SASL2: true}
+		}
+		for _, child := range el.AllChildren() {
+			switch sasl2Name(child) {
+			case "initial-response":
+				payload, err := decodePayload(child.Text())
+				if err != nil {
+					return nil, sasl2Failure(err)
+				}
+				if payload == nil {
+					payload = []byte{}
+				}
+				a.InitialResponse = payload
+			case "user-agent":
+				a.UserAgent = &UserAgent{ID: child.Attribute("id")}
+				if sw := sasl2Child(child, "software"); sw != nil {
+					a.UserAgent.Software = sw.Text()
+				}
+				if dev := sasl2Child(child, "device"); dev != nil {
+					a.UserAgent.Device = dev.Text()
+				}
+			default:
+				a.Other = append(a.Other, child)
+			}
+		}
+		return a, nil
+
+	case "challenge":
+		payload, err := decodePayload(el.Text())
+		if err != nil {
+			return nil, sasl2Failure(err)
+		}
+		return &Challenge{Payload: payload, SASL2: true}, nil
+
+	case "response":
+		payload, err := decodePayload(el.Text())
+		if err != nil {
+			return nil, sasl2Failure(err)
+		}
+		return &Response{Payload: payload, SASL2: true}, nil
+
+	case "success":
+		s := &Success{SASL2: true}
+		for _, child := range el.AllChildren() {
+			switch sasl2Name(child) {
+			case "additional-data":
+				payload, err := decodePayload(child.Text())
+				if err != nil {
+					return nil, sasl2Failure(err)
+				}
+				if payload == nil {
+					payload = []byte{}
+				}
+				s.AdditionalData = payload
+			case "authorization-identifier":
+				s.AuthorizationIdentifier = child.Text()
+			default:
+				s.Other = append(s.Other, child)
+			}
+		}
+		return s, nil
+
+	case "continue":
+		c := &Continue{}
+		if ad := sasl2Child(el, "additional-data"); ad != nil {
+			payload, err := decodePayload(ad.Text())
+			if err != nil {
+				return nil, sasl2Failure(err)
+			}
+			if payload == nil {
+				payload = []byte{}
+			}
+			c.AdditionalData = payload
+		}
+		if tasks := sasl2Child(el, "tasks"); tasks != nil {
+			for _, task := range sasl2Children(tasks, "task") {
+				c.Tasks = append(c.Tasks, task.Text())
+			}
+		}
+		if txt := sasl2Child(el, "text"); txt != nil {
+			c.Text = txt.Text()
+		}
+		return c, nil
+
+	case "failure":
+		return parseFailure(el, true), nil
+
+	case "abort":
+		a := &Abort{SASL2: true}
+		if txt := sasl2Child(el, "text"); txt != nil {
+			a.Text = txt.Text()
+		}
+		return a, nil
+
+	case "authentication":
+		a := &Authentication{}
+		for _, mechanism := range sasl2Children(el, "mechanism") {
+			a.Mechanisms = append(a.Mechanisms, mechanism.Text())
+		}
+		if inline := sasl2Child(el, "inline"); inline != nil {
+			a.Inline = inline.AllChildren()
+		}
+		return a, nil
+	}
+	return nil, &Failure{Condition: MalformedRequest, SASL2: true, Text: fmt.Sprintf("unexpected element: %s", stravaganza.LocalName(el))}
+}
+
+// sasl2Name returns el local name whenever it belongs to the SASL2 namespace,
+// either declared or inherited from its parent, and an empty string otherwise.
+func sasl2Name(el stravaganza.Element) string {
+	if ns := stravaganza.NamespaceURI(el); len(ns) > 0 && ns != SASL2Namespace {
+		return ""
+	}
+	return stravaganza.LocalName(el)
+}
+
+// sasl2Child returns the first el SASL2 child named name, or nil if none is found.
+func sasl2Child(el stravaganza.Element, name string) stravaganza.Element {
+	for _, child := range el.AllChildren() {
+		if sasl2Name(child) == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// sasl2Children returns all el SASL2 children named name.
+func sasl2Children(el stravaganza.Element, name string) []stravaganza.Element {
+	var children []stravaganza.Element
+	for _, child := range el.AllChildren() {
+		if sasl2Name(child) == name {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+func namespace(sasl2 bool) string {
+	if sasl2 {
+		return SASL2Namespace
+	}
+	return Namespace
+}
+
+func sasl2Failure(err error) error {
+	if f, ok := err.(*Failure); ok {
+		f.SASL2 = true
+	}
+	return err
+}
+
+func payloadElement(name string, payload []byte) stravaganza.Element {
+	return stravaganza.NewBuilder(name).
+		WithText(encodePayload(payload)).
+		Build()
+}
+
+func textElement(text, lang string) stravaganza.Element {
+	b := stravaganza.NewBuilder("text")
+	if len(lang) > 0 {
+		b.WithAttribute(stravaganza.Language, lang)
+	}
+	return b.WithText(text).Build()
+}
+
+// encodePayload returns the base64 encoding of a SASL payload.
+// As stated in RFC 6120 section 6.4.2, data of zero length is transmitted as a single equals sign.
+func encodePayload(b []byte) string {
+	switch {
+	case b == nil:
+		return ""
+	case len(b) == 0:
+		return "="
+	default:
+		return base64.StdEncoding.EncodeToString(b)
+	}
+}
+
+func decodePayload(s string) ([]byte, error) {
+	switch s {
+	case "":
+		return nil, nil
+	case "=":
+		return []byte{}, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, &Failure{Condition: IncorrectEncoding}
+	}
+	return b, nil
+}
@@ -0,0 +1,216 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppsasl
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza"
+	xmppparser "github.com/jackal-xmpp/stravaganza/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElements_ToXML(t *testing.T) {
+	var tcs = []struct {
+		name     string
+		el       Element
+		expected string
+	}{
+		{
+			name:     "Auth",
+			el:       &Auth{Mechanism: "PLAIN", InitialResponse: []byte("\x00user\x00pass")},
+			expected: `<auth xmlns='urn:ietf:params:xml:ns:xmpp-sasl' mechanism='PLAIN'>AHVzZXIAcGFzcw==</auth>`,
+		},
+		{
+			name:     "AuthEmptyInitialResponse",
+			el:       &Auth{Mechanism: "EXTERNAL", InitialResponse: []byte{}},
+			expected: `<auth xmlns='urn:ietf:params:xml:ns:xmpp-sasl' mechanism='EXTERNAL'>=</auth>`,
+		},
+		{
+			name:     "AuthNoInitialResponse",
+			el:       &Auth{Mechanism: "SCRAM-SHA-1"},
+			expected: `<auth xmlns='urn:ietf:params:xml:ns:xmpp-sasl' mechanism='SCRAM-SHA-1'/>`,
+		},
+		{
+			name:     "Challenge",
+			el:       &Challenge{Payload: []byte("r=abc")},
+			expected: `<challenge xmlns='urn:ietf:params:xml:ns:xmpp-sasl'>cj1hYmM=</challenge>`,
+		},
+		{
+			name:     "Response",
+			el:       &Response{SASL2: true},
+			expected: `<response xmlns='urn:xmpp:sasl:2'/>`,
+		},
+		{
+			name:     "Success",
+			el:       &Success{AdditionalData: []byte("v=abc")},
+			expected: `<success xmlns='urn:ietf:params:xml:ns:xmpp-sasl'>dj1hYmM=</success>`,
+		},
+		{
+			name: "SuccessSASL2",
+			el: &Success{
+				SASL2:                   true,
+				AdditionalData:          []byte("v=abc"),
+				AuthorizationIdentifier: "juliet@montague.example/Balcony",
+			},
+			expected: `<success xmlns='urn:xmpp:sasl:2'><additional-data>dj1hYmM=</additional-data>` +
+				`<authorization-identifier>juliet@montague.example/Balcony</authorization-identifier></success>`,
+		},
+		{
+			name: "Authenticate",
+			el: &Authenticate{
+				Mechanism:       "SCRAM-SHA-1-PLUS",
+				InitialResponse: []byte("p=tls-exporter,,n=user,r=12C4CD5C-E38E-4A98-8F6D-15C38F51CCC6"),
+				UserAgent: &UserAgent{
+					ID:       "d4565fa7-4d72-4749-b3d3-740edbf87770",
+					Software: "AwesomeXMPP",
+					Device:   "Kiva's Phone",
+				},
+			},
+			expected: `<authenticate xmlns='urn:xmpp:sasl:2' mechanism='SCRAM-SHA-1-PLUS'>` +
+				`<initial-response>cD10bHMtZXhwb3J0ZXIsLG49dXNlcixyPTEyQzRDRDVDLUUzOEUtNEE5OC04RjZELTE1QzM4RjUxQ0NDNg==</initial-response>` +
+				`<user-agent id='d4565fa7-4d72-4749-b3d3-740edbf87770'><software>AwesomeXMPP</software><device>Kiva&#39;s Phone</device></user-agent>` +
+				`</authenticate>`,
+		},
+		{
+			name:     "Continue",
+			el:       &Continue{AdditionalData: []byte("abc"), Tasks: []string{"HOTP-EXAMPLE"}, Text: "2FA required"},
+			expected: `<continue xmlns='urn:xmpp:sasl:2'><additional-data>YWJj</additional-data><tasks><task>HOTP-EXAMPLE</task></tasks><text>2FA required</text></continue>`,
+		},
+		{
+			name:     "Abort",
+			el:       &Abort{},
+			expected: `<abort xmlns='urn:ietf:params:xml:ns:xmpp-sasl'/>`,
+		},
+		{
+			name:     "Authentication",
+			el:       &Authentication{Mechanisms: []string{"SCRAM-SHA-1", "PLAIN"}},
+			expected: `<authentication xmlns='urn:xmpp:sasl:2'><mechanism>SCRAM-SHA-1</mechanism><mechanism>PLAIN</mechanism></authentication>`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			el := tc.el.Element()
+			require.Equal(t, tc.expected, el.String())
+
+			parsed, err := Parse(el)
+			require.Nil(t, err)
+			require.Equal(t, tc.el, parsed)
+		})
+	}
+}
+
+func TestElements_ParseFromStream(t *testing.T) {
+	// given
+	src := `<stream:stream xmlns:stream="http://etherx.jabber.org/streams" xmlns="jabber:client" version="1.0">` +
+		`<challenge xmlns="urn:ietf:params:xml:ns:xmpp-sasl">cj1hYmM=</challenge>` +
+		`<success xmlns="urn:xmpp:sasl:2"><additional-data>=</additional-data>` +
+		`<authorization-identifier>juliet@montague.example</authorization-identifier>` +
+		`<bound xmlns="urn:xmpp:bind:0"/></success>`
+	p := xmppparser.New(strings.NewReader(src), xmppparser.SocketStream, 0, xmppparser.WithNamespaceResolution())
+	_, err := p.Parse()
+	require.Nil(t, err)
+
+	// when
+	el1, _ := p.Parse()
+	el2, _ := p.Parse()
+	c, err1 := Parse(el1)
+	s, err2 := Parse(el2)
+
+	// then
+	require.Nil(t, err1)
+	require.Nil(t, err2)
+	require.Equal(t, &Challenge{Payload: []byte("r=abc")}, c)
+
+	success := s.(*Success)
+	require.True(t, success.SASL2)
+	require.Equal(t, []byte{}, success.AdditionalData)
+	require.Equal(t, "juliet@montague.example", success.AuthorizationIdentifier)
+	require.Len(t, success.Other, 1)
+	require.Equal(t, "bound", success.Other[0].Name())
+}
+
+func TestElements_ParseSASL2ChildNamespaces(t *testing.T) {
+	// given
+	src := `<authenticate xmlns="urn:xmpp:sasl:2" mechanism="PLAIN">` +
+		`<initial-response xmlns="urn:example:other">Zm9yZWlnbg==</initial-response>` +
+		`<s:initial-response xmlns:s="urn:xmpp:sasl:2">AGp1bGlldAByMG0zMG15cjBtMzA=</s:initial-response>` +
+		`<user-agent id="d4565fa7-4d72-4749-b3d3-740edbf87770"><software>AwesomeXMPP</software></user-agent>` +
+		`</authenticate>`
+	p := xmppparser.New(strings.NewReader(src), xmppparser.DefaultMode, 0, xmppparser.WithNamespaceResolution())
+	el, err := p.Parse()
+	require.Nil(t, err)
+
+	// when
+	a, err := Parse(el)
+
+	// then
+	require.Nil(t, err)
+
+	authenticate := a.(*Authenticate)
+	require.Equal(t, []byte("\x00juliet\x00r0m30myr0m30"), authenticate.InitialResponse)
+	require.Equal(t, "AwesomeXMPP", authenticate.UserAgent.Software)
+	require.Len(t, authenticate.Other, 1)
+	require.Equal(t, "urn:example:other", stravaganza.NamespaceURI(authenticate.Other[0]))
+}
+
+func TestElements_ParseErrors(t *testing.T) {
+	var tcs = []struct {
+		name      string
+		el        stravaganza.Element
+		condition Condition
+	}{
+		{
+			name: "IncorrectEncoding",
+			el: stravaganza.NewBuilder("response").
+				WithAttribute(stravaganza.Namespace, Namespace).
+				WithText("***").
+				Build(),
+			condition: IncorrectEncoding,
+		},
+		{
+			name: "MissingMechanism",
+			el: stravaganza.NewBuilder("auth").
+				WithAttribute(stravaganza.Namespace, Namespace).
+				Build(),
+			condition: InvalidMechanism,
+		},
+		{
+			name: "UnknownElement",
+			el: stravaganza.NewBuilder("foo").
+				WithAttribute(stravaganza.Namespace, SASL2Namespace).
+				Build(),
+			condition: MalformedRequest,
+		},
+		{
+			name: "UnknownNamespace",
+			el: stravaganza.NewBuilder("auth").
+				WithAttribute(stravaganza.Namespace, "urn:other").
+				Build(),
+			condition: MalformedRequest,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.el)
+
+			var f *Failure
+			require.True(t, errors.As(err, &f))
+			require.Equal(t, tc.condition, f.Condition)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppsasl
+
+import (
+	"fmt"
+
+	"github.com/jackal-xmpp/stravaganza"
+	"github.com/jackal-xmpp/stravaganza/internal/mapsutil"
+)
+
+// Condition is the SASL failure defined condition.
+type Condition uint8
+
+const (
+	Aborted              Condition = iota // Aborted.
+	AccountDisabled                       // Account disabled.
+	CredentialsExpired                    // Credentials expired.
+	EncryptionRequired                    // Encryption required.
+	IncorrectEncoding                     // Incorrect encoding.
+	InvalidAuthzID                        // Invalid authzid.
+	InvalidMechanism                      // Invalid mechanism.
+	MalformedRequest                      // Malformed request.
+	MechanismTooWeak                      // Mechanism too weak.
+	NotAuthorized                         // Not authorized.
+	TemporaryAuthFailure                  // Temporary auth failure.
+)
+
+var condition2Str = map[Condition]string{
+	Aborted:              "aborted",
+	AccountDisabled:      "account-disabled",
+	CredentialsExpired:   "credentials-expired",
+	EncryptionRequired:   "encryption-required",
+	IncorrectEncoding:    "incorrect-encoding",
+	InvalidAuthzID:       "invalid-authzid",
+	InvalidMechanism:     "invalid-mechanism",
+	MalformedRequest:     "malformed-request",
+	MechanismTooWeak:     "mechanism-too-weak",
+	NotAuthorized:        "not-authorized",
+	TemporaryAuthFailure: "temporary-auth-failure",
+}
+
+var str2Condition = mapsutil.Reverse(condition2Str)
+
+// String returns string failure condition representation.
+func (c Condition) String() string { return condition2Str[c] }
+
+// Failure represents a <failure/> element.
+type Failure struct {
+	// Condition is the failure defined condition.
+	Condition Condition
+
+	// Lang is the failure text lang code.
+	Lang string
+
+	// Text is the failure descriptive text.
+	Text string
+
+	// SASL2 tells whether the element belongs to the SASL2 namespace.
+	SASL2 bool
+
+	// ApplicationElement defines the application specific condition element. Only applies to SASL2.
+	ApplicationElement stravaganza.Element
+}
+
+// Element satisfies Element interface.
+func (f *Failure) Element() stravaganza.Element {
+	b := stravaganza.NewBuilder("failure").
+		WithAttribute(stravaganza.Namespace, namespace(f.SASL2))
+
+	cb := stravaganza.NewBuilder(f.Condition.String())
+	if f.SASL2 {
+		cb.WithAttribute(stravaganza.Namespace, Namespace)
+	}
+	b.WithChild(cb.Build())
+
+	if f.SASL2 && f.ApplicationElement != nil {
+		b.WithChild(f.ApplicationElement)
+	}
+	if len(f.Text) > 0 {
+		b.WithChild(textElement(f.Text, f.Lang))
+	}
+	return b.Build()
+}
+
+// Error satisfies error interface.
+func (f *Failure) Error() string {
+	if len(f.Text) > 0 {
+		return fmt.Sprintf("%s: %s", f.Condition.String(), f.Text)
+	}
+	return f.Condition.String()
+}
+
+// parseFailure parses a failure element.
+// Unknown defined conditions are reported as NotAuthorized failures.
+func parseFailure(el stravaganza.Element, sasl2 bool) *Failure {
+	f := &Failure{Condition: NotAuthorized, SASL2: sasl2}
+
+	var hasCondition bool
+	for _, child := range el.AllChildren() {
+		ns := stravaganza.NamespaceURI(child)
+		switch {
+		case stravaganza.LocalName(child) == "text" && (len(ns) == 0 || ns == stravaganza.NamespaceURI(el)):
+			f.Text = child.Text()
+			f.Lang = child.Attribute(stravaganza.Language)
+		case ns == Namespace || (!sasl2 && len(ns) == 0):
+			if hasCondition {
+				continue
+			}
+			if c, ok := str2Condition[stravaganza.LocalName(child)]; ok {
+				f.Condition = c
+			}
+			hasCondition = true
+		default:
+			if sasl2 && f.ApplicationElement == nil {
+				f.ApplicationElement = child
+			}
+		}
+	}
+	return f
+}
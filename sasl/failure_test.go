@@ -0,0 +1,81 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppsasl
+
+import (
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailure_Element(t *testing.T) {
+	// given
+	f1 := &Failure{Condition: AccountDisabled, Text: "Call 212-555-1212 for help.", Lang: "en"}
+	f2 := &Failure{
+		Condition:          Aborted,
+		Text:               "This is a terrible example.",
+		SASL2:              true,
+		ApplicationElement: stravaganza.NewBuilder("optional-application-specific").WithAttribute(stravaganza.Namespace, "urn:something:else").Build(),
+	}
+
+	// when
+	el1 := f1.Element()
+	el2 := f2.Element()
+
+	// then
+	require.Equal(t, `<failure xmlns='urn:ietf:params:xml:ns:xmpp-sasl'><account-disabled/>`+
+		`<text xml:lang='en'>Call 212-555-1212 for help.</text></failure>`, el1.String())
+	require.Equal(t, `<failure xmlns='urn:xmpp:sasl:2'><aborted xmlns='urn:ietf:params:xml:ns:xmpp-sasl'/>`+
+		`<optional-application-specific xmlns='urn:something:else'/>`+
+		`<text>This is a terrible example.</text></failure>`, el2.String())
+
+	p1, err1 := Parse(el1)
+	p2, err2 := Parse(el2)
+	require.Nil(t, err1)
+	require.Nil(t, err2)
+	require.Equal(t, f1, p1)
+	require.Equal(t, f2.Condition, p2.(*Failure).Condition)
+	require.Equal(t, f2.Text, p2.(*Failure).Text)
+	require.Equal(t, "optional-application-specific", p2.(*Failure).ApplicationElement.Name())
+}
+
+func TestFailure_Conditions(t *testing.T) {
+	for c, str := range condition2Str {
+		el := (&Failure{Condition: c}).Element()
+		require.Equal(t, str, el.AllChildren()[0].Name())
+
+		f, err := Parse(el)
+		require.Nil(t, err)
+		require.Equal(t, c, f.(*Failure).Condition)
+	}
+}
+
+func TestFailure_UnknownCondition(t *testing.T) {
+	el := stravaganza.NewBuilder("failure").
+		WithAttribute(stravaganza.Namespace, Namespace).
+		WithChild(stravaganza.NewBuilder("unknown-condition").Build()).
+		Build()
+
+	f, err := Parse(el)
+
+	require.Nil(t, err)
+	require.Equal(t, NotAuthorized, f.(*Failure).Condition)
+}
+
+func TestFailure_Error(t *testing.T) {
+	var err error = &Failure{Condition: NotAuthorized, Text: "bad credentials"}
+	require.Equal(t, "not-authorized: bad credentials", err.Error())
+}
@@ -0,0 +1,86 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppsasl
+
+import (
+	"errors"
+)
+
+// ErrUnexpectedChallenge will be returned by Next when a mechanism doesn't expect any further challenge.
+var ErrUnexpectedChallenge = errors.New("xmppsasl: unexpected challenge")
+
+// Mechanism defines the interface implemented by client side SASL mechanisms.
+// A Mechanism value holds negotiation state, so it must not be reused across authentication attempts.
+type Mechanism interface {
+	// Name returns the mechanism name, as advertised by the receiving entity.
+	Name() string
+
+	// Start returns the initial response to be sent along with the selected mechanism.
+	// A nil value means no initial response, while an empty non-nil value represents
+	// an initial response of zero length.
+	Start() ([]byte, error)
+
+	// Next returns the response to a receiving entity challenge.
+	Next(challenge []byte) ([]byte, error)
+
+	// Finish validates the additional data received along with success, if any.
+	Finish(additionalData []byte) error
+}
+
+type plain struct {
+	authzID, username, password string
+}
+
+// NewPlain returns a PLAIN mechanism (RFC 4616).
+// authzID is optional, and it should be empty unless the entity wants to act on behalf of another one.
+func NewPlain(authzID, username, password string) Mechanism {
+	return &plain{authzID: authzID, username: username, password: password}
+}
+
+func (m *plain) Name() string { return "PLAIN" }
+
+func (m *plain) Start() ([]byte, error) {
+	b := make([]byte, 0, len(m.authzID)+len(m.username)+len(m.password)+2)
+	b = append(b, m.authzID...)
+	b = append(b, 0)
+	b = append(b, m.username...)
+	b = append(b, 0)
+	b = append(b, m.password...)
+	return b, nil
+}
+
+func (m *plain) Next(_ []byte) ([]byte, error) { return nil, ErrUnexpectedChallenge }
+
+func (m *plain) Finish(_ []byte) error { return nil }
+
+type external struct {
+	authzID string
+}
+
+// NewExternal returns an EXTERNAL mechanism (RFC 4422 appendix A), as used along
+// with TLS client certificates (XEP-0178).
+// authzID is optional, and when empty the authorization identity will be derived
+// by the receiving entity from the credentials.
+func NewExternal(authzID string) Mechanism {
+	return &external{authzID: authzID}
+}
+
+func (m *external) Name() string { return "EXTERNAL" }
+
+func (m *external) Start() ([]byte, error) { return []byte(m.authzID), nil }
+
+func (m *external) Next(_ []byte) ([]byte, error) { return nil, ErrUnexpectedChallenge }
+
+func (m *external) Finish(_ []byte) error { return nil }
@@ -0,0 +1,66 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppsasl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMechanism_Plain(t *testing.T) {
+	// given
+	m := NewPlain("", "juliet", "r0m30myr0m30")
+
+	// when
+	resp, err := m.Start()
+	_, nextErr := m.Next(nil)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, "PLAIN", m.Name())
+	require.Equal(t, "AGp1bGlldAByMG0zMG15cjBtMzA=", encodePayload(resp))
+	require.Equal(t, ErrUnexpectedChallenge, nextErr)
+	require.Nil(t, m.Finish(nil))
+}
+
+func TestMechanism_PlainAuthzID(t *testing.T) {
+	m := NewPlain("romeo@example.net", "juliet", "pass")
+
+	resp, err := m.Start()
+
+	require.Nil(t, err)
+	require.Equal(t, "romeo@example.net\x00juliet\x00pass", string(resp))
+}
+
+func TestMechanism_External(t *testing.T) {
+	// given
+	m1 := NewExternal("")
+	m2 := NewExternal("juliet@example.com")
+
+	// when
+	resp1, err1 := m1.Start()
+	resp2, err2 := m2.Start()
+
+	// then
+	require.Nil(t, err1)
+	require.Nil(t, err2)
+	require.Equal(t, "EXTERNAL", m1.Name())
+	require.Equal(t, "=", encodePayload(resp1))
+	require.Equal(t, "juliet@example.com", string(resp2))
+
+	_, err := m1.Next([]byte("x"))
+	require.Equal(t, ErrUnexpectedChallenge, err)
+}
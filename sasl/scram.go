@@ -0,0 +1,292 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppsasl
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+const (
+	scramNonceSize     = 24
+	scramMaxIterations = 1 << 20
+)
+
+var errInvalidServerSignature = errors.New("xmppsasl: invalid scram server signature")
+
+// SCRAMOption defines a SCRAM mechanism option.
+type SCRAMOption func(*scram)
+
+// WithAuthzID sets the SCRAM authorization identity.
+func WithAuthzID(authzID string) SCRAMOption {
+	return func(m *scram) {
+		m.authzID = authzID
+	}
+}
+
+// WithNonce sets a fixed client nonce, instead of a randomly generated one.
+// It's intended to be used only for testing purposes.
+func WithNonce(nonce string) SCRAMOption {
+	return func(m *scram) {
+		m.clientNonce = nonce
+	}
+}
+
+// WithChannelBinding enables channel binding, turning the mechanism into its -PLUS variant.
+// cbType is the channel binding type (e.g. 'tls-exporter' or 'tls-server-end-point'), and data
+// is the channel binding data obtained from the underlying TLS connection.
+func WithChannelBinding(cbType string, data []byte) SCRAMOption {
+	return func(m *scram) {
+		m.cbType = cbType
+		m.cbData = data
+	}
+}
+
+type scramStep uint8
+
+const (
+	scramStart scramStep = iota
+	scramClientFirst
+	scramClientFinal
+	scramDone
+)
+
+type scram struct {
+	name     string
+	h        func() hash.Hash
+	username string
+	password string
+	authzID  string
+	cbType   string
+	cbData   []byte
+
+	step            scramStep
+	clientNonce     string
+	clientFirstBare string
+	serverSignature []byte
+}
+
+// NewSCRAMSHA1 returns a SCRAM-SHA-1 mechanism (RFC 5802).
+// username and password are expected to be already prepared according to SASLprep.
+func NewSCRAMSHA1(username, password string, opts ...SCRAMOption) Mechanism {
+	return newSCRAM("SCRAM-SHA-1", sha1.New, username, password, opts)
+}
+
+// NewSCRAMSHA256 returns a SCRAM-SHA-256 mechanism (RFC 7677).
+// username and password are expected to be already prepared according to SASLprep.
+func NewSCRAMSHA256(username, password string, opts ...SCRAMOption) Mechanism {
+	return newSCRAM("SCRAM-SHA-256", sha256.New, username, password, opts)
+}
+
+// NewSCRAMSHA512 returns a SCRAM-SHA-512 mechanism.
+// username and password are expected to be already prepared according to SASLprep.
+func NewSCRAMSHA512(username, password string, opts ...SCRAMOption) Mechanism {
+	return newSCRAM("SCRAM-SHA-512", sha512.New, username, password, opts)
+}
+
+func newSCRAM(name string, h func() hash.Hash, username, password string, opts []SCRAMOption) *scram {
+	m := &scram{
+		name:     name,
+		h:        h,
+		username: username,
+		password: password,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if len(m.cbType) > 0 {
+		m.name += "-PLUS"
+	}
+	return m
+}
+
+func (m *scram) Name() string { return m.name }
+
+func (m *scram) Start() ([]byte, error) {
+	if m.step != scramStart {
+		return nil, errors.New("xmppsasl: scram negotiation already started")
+	}
+	if len(m.clientNonce) == 0 {
+		b := make([]byte, scramNonceSize)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		m.clientNonce = base64.RawStdEncoding.EncodeToString(b)
+	}
+	m.clientFirstBare = "n=" + saslName(m.username) + ",r=" + m.clientNonce
+	m.step = scramClientFirst
+	return []byte(m.gs2Header() + m.clientFirstBare), nil
+}
+
+func (m *scram) Next(challenge []byte) ([]byte, error) {
+	switch m.step {
+	case scramClientFirst:
+		return m.clientFinal(string(challenge))
+
+	case scramClientFinal:
+		if err := m.verifyServerFinal(string(challenge)); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return nil, ErrUnexpectedChallenge
+}
+
+func (m *scram) Finish(additionalData []byte) error {
+	switch {
+	case len(additionalData) > 0:
+		if m.step != scramClientFinal && m.step != scramDone {
+			return errors.New("xmppsasl: unexpected scram success")
+		}
+		return m.verifyServerFinal(string(additionalData))
+	case m.step != scramDone:
+		return errors.New("xmppsasl: missing scram server signature")
+	}
+	return nil
+}
+
+func (m *scram) gs2Header() string {
+	var sb strings.Builder
+	if len(m.cbType) > 0 {
+		sb.WriteString("p=")
+		sb.WriteString(m.cbType)
+	} else {
+		sb.WriteString("n")
+	}
+	sb.WriteString(",")
+	if len(m.authzID) > 0 {
+		sb.WriteString("a=")
+		sb.WriteString(saslName(m.authzID))
+	}
+	sb.WriteString(",")
+	return sb.String()
+}
+
+func (m *scram) clientFinal(serverFirst string) ([]byte, error) {
+	var nonce, salt, iterations string
+	for i, attr := range strings.Split(serverFirst, ",") {
+		if len(attr) < 2 || attr[1] != '=' {
+			return nil, fmt.Errorf("xmppsasl: malformed scram server-first message: %q", serverFirst)
+		}
+		switch attr[0] {
+		case 'r':
+			nonce = attr[2:]
+		case 's':
+			salt = attr[2:]
+		case 'i':
+			iterations = attr[2:]
+		case 'm':
+			if i == 0 {
+				return nil, errors.New("xmppsasl: unsupported scram mandatory extension")
+			}
+		}
+	}
+	if !strings.HasPrefix(nonce, m.clientNonce) || len(nonce) == len(m.clientNonce) {
+		return nil, errors.New("xmppsasl: invalid scram server nonce")
+	}
+	saltBytes, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil || len(saltBytes) == 0 {
+		return nil, errors.New("xmppsasl: invalid scram salt")
+	}
+	iterCount, err := strconv.Atoi(iterations)
+	if err != nil || iterCount <= 0 || iterCount > scramMaxIterations {
+		return nil, fmt.Errorf("xmppsasl: invalid scram iteration count: %q", iterations)
+	}
+
+	cbInput := append([]byte(m.gs2Header()), m.cbData...)
+	clientFinalWithoutProof := "c=" + base64.StdEncoding.EncodeToString(cbInput) + ",r=" + nonce
+	authMessage := []byte(m.clientFirstBare + "," + serverFirst + "," + clientFinalWithoutProof)
+
+	saltedPassword := hi(m.h, []byte(m.password), saltBytes, iterCount)
+	clientKey := hmacSum(m.h, saltedPassword, []byte("Client Key"))
+	storedKey := hashSum(m.h, clientKey)
+	clientSignature := hmacSum(m.h, storedKey, authMessage)
+
+	clientProof := make([]byte, len(clientKey))
+	for i := range clientKey {
+		clientProof[i] = clientKey[i] ^ clientSignature[i]
+	}
+	serverKey := hmacSum(m.h, saltedPassword, []byte("Server Key"))
+	m.serverSignature = hmacSum(m.h, serverKey, authMessage)
+	m.step = scramClientFinal
+
+	return []byte(clientFinalWithoutProof + ",p=" + base64.StdEncoding.EncodeToString(clientProof)), nil
+}
+
+func (m *scram) verifyServerFinal(serverFinal string) error {
+	switch {
+	case strings.HasPrefix(serverFinal, "e="):
+		return fmt.Errorf("xmppsasl: scram server error: %s", serverFinal[2:])
+	case !strings.HasPrefix(serverFinal, "v="):
+		return fmt.Errorf("xmppsasl: malformed scram server-final message: %q", serverFinal)
+	}
+	v, _, _ := strings.Cut(serverFinal[2:], ",")
+	signature, err := base64.StdEncoding.DecodeString(v)
+	if err != nil || !hmac.Equal(signature, m.serverSignature) {
+		return errInvalidServerSignature
+	}
+	m.step = scramDone
+	return nil
+}
+
+// hi implements the SCRAM Hi function, which is PBKDF2 (RFC 8018) using HMAC as pseudorandom function
+// and an output length equal to the hash size.
+func hi(h func() hash.Hash, password, salt []byte, iterations int) []byte {
+	mac := hmac.New(h, password)
+	mac.Write(salt)
+	mac.Write([]byte{0, 0, 0, 1})
+	u := mac.Sum(nil)
+
+	out := make([]byte, len(u))
+	copy(out, u)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range out {
+			out[j] ^= u[j]
+		}
+	}
+	return out
+}
+
+func hmacSum(h func() hash.Hash, key, data []byte) []byte {
+	mac := hmac.New(h, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func hashSum(h func() hash.Hash, data []byte) []byte {
+	hh := h()
+	hh.Write(data)
+	return hh.Sum(nil)
+}
+
+// saslName escapes ',' and '=' characters, as required by SCRAM username and authzid attributes.
+func saslName(s string) string {
+	if !strings.ContainsAny(s, ",=") {
+		return s
+	}
+	return strings.NewReplacer("=", "=3D", ",", "=2C").Replace(s)
+}
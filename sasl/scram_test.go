@@ -0,0 +1,184 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppsasl
+
+import (
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSCRAM_SHA1(t *testing.T) {
+	// RFC 5802 section 5 test vector
+
+	// given
+	m := NewSCRAMSHA1("user", "pencil", WithNonce("fyko+d2lbbFgONRv9qkxdawL"))
+
+	// when
+	clientFirst, err1 := m.Start()
+	clientFinal, err2 := m.Next([]byte("r=fyko+d2lbbFgONRv9qkxdawL3rfcNHYJY1ZVvWVs7j,s=QSXCR+Q6sek8bf92,i=4096"))
+	err3 := m.Finish([]byte("v=rmF9pqV8S7suAoZWja4dJRkFsKQ="))
+
+	// then
+	require.Nil(t, err1)
+	require.Nil(t, err2)
+	require.Nil(t, err3)
+	require.Equal(t, "SCRAM-SHA-1", m.Name())
+	require.Equal(t, "n,,n=user,r=fyko+d2lbbFgONRv9qkxdawL", string(clientFirst))
+	require.Equal(t, "c=biws,r=fyko+d2lbbFgONRv9qkxdawL3rfcNHYJY1ZVvWVs7j,p=v0X8v3Bz2T0CJGbJQyF0X+HI4Ts=", string(clientFinal))
+}
+
+func TestSCRAM_SHA256(t *testing.T) {
+	// RFC 7677 section 3 test vector
+
+	// given
+	m := NewSCRAMSHA256("user", "pencil", WithNonce("rOprNGfwEbeRWgbNEkqO"))
+
+	// when
+	clientFirst, err1 := m.Start()
+	clientFinal, err2 := m.Next([]byte("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"))
+	resp, err3 := m.Next([]byte("v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4="))
+	err4 := m.Finish(nil)
+
+	// then
+	require.Nil(t, err1)
+	require.Nil(t, err2)
+	require.Nil(t, err3)
+	require.Nil(t, err4)
+	require.Nil(t, resp)
+	require.Equal(t, "n,,n=user,r=rOprNGfwEbeRWgbNEkqO", string(clientFirst))
+	require.Equal(t, "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=", string(clientFinal))
+}
+
+func TestSCRAM_SHA512Plus(t *testing.T) {
+	// given
+	cbData := []byte{0xde, 0xad, 0xbe, 0xef}
+	m := NewSCRAMSHA512("user,name", "pencil",
+		WithNonce("abcdef"),
+		WithAuthzID("admin=1"),
+		WithChannelBinding("tls-exporter", cbData),
+	)
+	serverFirst := "r=abcdefghijkl,s=" + base64.StdEncoding.EncodeToString([]byte("salt")) + ",i=16"
+
+	// when
+	clientFirst, err1 := m.Start()
+	clientFinal, err2 := m.Next([]byte(serverFirst))
+
+	// then
+	require.Nil(t, err1)
+	require.Nil(t, err2)
+	require.Equal(t, "SCRAM-SHA-512-PLUS", m.Name())
+
+	gs2Header := "p=tls-exporter,a=admin=3D1,"
+	clientFirstBare := "n=user=2Cname,r=abcdef"
+	require.Equal(t, gs2Header+clientFirstBare, string(clientFirst))
+
+	cb := base64.StdEncoding.EncodeToString(append([]byte(gs2Header), cbData...))
+	clientFinalWithoutProof := "c=" + cb + ",r=abcdefghijkl"
+	require.True(t, strings.HasPrefix(string(clientFinal), clientFinalWithoutProof+",p="))
+
+	// verify proof and compute server signature as the receiving entity would
+	authMessage := []byte(clientFirstBare + "," + serverFirst + "," + clientFinalWithoutProof)
+	saltedPassword := hi(sha512.New, []byte("pencil"), []byte("salt"), 16)
+	storedKey := hashSum(sha512.New, hmacSum(sha512.New, saltedPassword, []byte("Client Key")))
+	clientSignature := hmacSum(sha512.New, storedKey, authMessage)
+
+	proof, err := base64.StdEncoding.DecodeString(string(clientFinal[len(clientFinalWithoutProof)+3:]))
+	require.Nil(t, err)
+	clientKey := make([]byte, len(proof))
+	for i := range proof {
+		clientKey[i] = proof[i] ^ clientSignature[i]
+	}
+	require.Equal(t, storedKey, hashSum(sha512.New, clientKey))
+
+	serverSignature := hmacSum(sha512.New, hmacSum(sha512.New, saltedPassword, []byte("Server Key")), authMessage)
+	require.Nil(t, m.Finish([]byte("v="+base64.StdEncoding.EncodeToString(serverSignature))))
+}
+
+func TestSCRAM_Errors(t *testing.T) {
+	var tcs = []struct {
+		name        string
+		serverFirst string
+	}{
+		{name: "MismatchingNonce", serverFirst: "r=other,s=QSXCR+Q6sek8bf92,i=4096"},
+		{name: "MissingServerNonce", serverFirst: "r=fyko,s=QSXCR+Q6sek8bf92,i=4096"},
+		{name: "InvalidSalt", serverFirst: "r=fyko123,s=***,i=4096"},
+		{name: "InvalidIterations", serverFirst: "r=fyko123,s=QSXCR+Q6sek8bf92,i=0"},
+		{name: "TooManyIterations", serverFirst: "r=fyko123,s=QSXCR+Q6sek8bf92,i=999999999"},
+		{name: "MandatoryExtension", serverFirst: "m=ext,r=fyko123,s=QSXCR+Q6sek8bf92,i=4096"},
+		{name: "Malformed", serverFirst: "garbage"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewSCRAMSHA1("user", "pencil", WithNonce("fyko"))
+			_, _ = m.Start()
+
+			_, err := m.Next([]byte(tc.serverFirst))
+			require.NotNil(t, err)
+		})
+	}
+}
+
+func TestSCRAM_InvalidServerSignature(t *testing.T) {
+	// given
+	m := NewSCRAMSHA1("user", "pencil", WithNonce("fyko+d2lbbFgONRv9qkxdawL"))
+	_, _ = m.Start()
+	_, _ = m.Next([]byte("r=fyko+d2lbbFgONRv9qkxdawL3rfcNHYJY1ZVvWVs7j,s=QSXCR+Q6sek8bf92,i=4096"))
+
+	// when
+	err1 := m.Finish([]byte("v=AAAAAAAAAAAAAAAAAAAAAAAAAAA="))
+	err2 := m.Finish(nil)
+	_, err3 := m.Next([]byte("e=invalid-proof"))
+
+	// then
+	require.Equal(t, errInvalidServerSignature, err1)
+	require.NotNil(t, err2)
+	require.NotNil(t, err3)
+}
+
+func TestSCRAM_RandomNonce(t *testing.T) {
+	m1 := NewSCRAMSHA256("user", "pencil")
+	m2 := NewSCRAMSHA256("user", "pencil")
+
+	b1, err1 := m1.Start()
+	b2, err2 := m2.Start()
+
+	require.Nil(t, err1)
+	require.Nil(t, err2)
+	require.NotEqual(t, b1, b2)
+	require.True(t, strings.HasPrefix(string(b1), "n,,n=user,r="))
+}
+
+func TestSCRAM_Hi(t *testing.T) {
+	// RFC 6070 PBKDF2-HMAC-SHA1 test vectors
+	var tcs = []struct {
+		password, salt string
+		iterations     int
+		expected       string
+	}{
+		{password: "password", salt: "salt", iterations: 1, expected: "0c60c80f961f0e71f3a9b524af6012062fe037a6"},
+		{password: "password", salt: "salt", iterations: 2, expected: "ea6c014dc72d6f8ccd1ed92ace1d41f0d8de8957"},
+		{password: "password", salt: "salt", iterations: 4096, expected: "4b007901b765489abead49d926f721d065a429c1"},
+	}
+	for _, tc := range tcs {
+		out := hi(sha1.New, []byte(tc.password), []byte(tc.salt), tc.iterations)
+		require.Equal(t, tc.expected, hex.EncodeToString(out))
+	}
+}
@@ -0,0 +1,530 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package selector implements a small XPath-like query language over element trees.
+//
+// A selector expression is made of steps separated by '/' (child axis) or '//' (descendant axis).
+// Expressions are evaluated relative to the element passed to the selector, so that
+// 'pubsub/items/item' matches all 'item' elements under 'items' under a 'pubsub' child element.
+// A leading '/' makes the first step match the context element itself, while a leading '//'
+// makes the first step match any descendant element.
+//
+// Every step is made of a name test optionally followed by one or more predicates:
+//
+//	name            element name, as found in the document (including its prefix if any)
+//	*               any element
+//	{ns}name        element local name and resolved namespace URI
+//	{ns}*           any element within namespace
+//	[n]             n-th matching element (1-based)
+//	[last()]        last matching element
+//	[@attr]         element has an 'attr' attribute
+//	[@attr='v']     element 'attr' attribute value equals v
+//	[@attr!='v']    element 'attr' attribute value is not equal to v
+//	[text()='v']    element text equals v
+//
+// A selector might end with a 'text()' step, to select element texts, or an '@attr' step,
+// to select attribute values.
+package selector
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackal-xmpp/stravaganza"
+)
+
+type resultKind int
+
+const (
+	elementResult resultKind = iota
+	textResult
+	attributeResult
+)
+
+type predicateKind int
+
+const (
+	positionPredicate predicateKind = iota
+	lastPredicate
+	hasAttributePredicate
+	attributeEqualsPredicate
+	attributeNotEqualsPredicate
+	textEqualsPredicate
+)
+
+type predicate struct {
+	kind     predicateKind
+	position int
+	name     string
+	value    string
+}
+
+type step struct {
+	descendant bool
+	any        bool
+	name       string
+	ns         string
+	matchNS    bool
+	predicates []predicate
+}
+
+// Selector represents a compiled selector expression.
+// A Selector is safe for concurrent use by multiple goroutines.
+type Selector struct {
+	expr     string
+	self     bool
+	steps    []step
+	result   resultKind
+	attrName string
+}
+
+// Compile parses a selector expression and returns, if successful,
+// a Selector that can be used to match against elements.
+func Compile(expr string) (*Selector, error) {
+	p := &exprParser{expr: expr}
+	s, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("selector: %s: %v", expr, err)
+	}
+	return s, nil
+}
+
+// MustCompile is like Compile but panics if the expression cannot be parsed.
+func MustCompile(expr string) *Selector {
+	s, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// String returns the source expression used to compile the selector.
+func (s *Selector) String() string {
+	return s.expr
+}
+
+// Elements returns all elements matched by the selector in document order.
+// In case the selector ends with a 'text()' or an '@attr' step, the elements owning
+// the selected values are returned.
+func (s *Selector) Elements(el stravaganza.Element) []stravaganza.Element {
+	if el == nil {
+		return nil
+	}
+	t := newTree(el)
+	ctx := []int{0}
+	for i, st := range s.steps {
+		if i == 0 && s.self {
+			if !st.matches(el) {
+				return nil
+			}
+			ctx = st.filter(t, ctx)
+			continue
+		}
+		var next []int
+		for _, parent := range ctx {
+			if st.descendant {
+				for pos := parent; pos < t.ends[parent]; pos++ {
+					next = append(next, st.filter(t, st.children(t, pos))...)
+				}
+				continue
+			}
+			next = append(next, st.filter(t, st.children(t, parent))...)
+		}
+		if st.descendant {
+			// nested context elements select the same descendants more than once
+			next = inDocumentOrder(next)
+		}
+		ctx = next
+		if len(ctx) == 0 {
+			return nil
+		}
+	}
+	ret := make([]stravaganza.Element, 0, len(ctx))
+	for _, pos := range ctx {
+		e := t.elements[pos]
+		if s.result == attributeResult && !hasAttribute(e, s.attrName) {
+			continue
+		}
+		ret = append(ret, e)
+	}
+	if len(ret) == 0 {
+		return nil
+	}
+	return ret
+}
+
+// First returns first element matched by the selector, or nil if none is found.
+func (s *Selector) First(el stravaganza.Element) stravaganza.Element {
+	elements := s.Elements(el)
+	if len(elements) == 0 {
+		return nil
+	}
+	return elements[0]
+}
+
+// Strings returns all values matched by the selector in document order.
+// Selected attribute values are returned in case the selector ends with an '@attr' step,
+// otherwise matched element texts are returned.
+func (s *Selector) Strings(el stravaganza.Element) []string {
+	elements := s.Elements(el)
+	if len(elements) == 0 {
+		return nil
+	}
+	values := make([]string, len(elements))
+	for i, e := range elements {
+		if s.result == attributeResult {
+			values[i] = e.Attribute(s.attrName)
+		} else {
+			values[i] = e.Text()
+		}
+	}
+	return values
+}
+
+// Value returns first value matched by the selector, or an empty string if none is found.
+func (s *Selector) Value(el stravaganza.Element) string {
+	values := s.Strings(el)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (st *step) children(t *tree, parent int) []int {
+	var children []int
+	for _, child := range t.children(parent) {
+		if st.matches(t.elements[child]) {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+func (st *step) matches(el stravaganza.Element) bool {
+	if st.matchNS {
+		if stravaganza.NamespaceURI(el) != st.ns {
+			return false
+		}
+		return st.any || stravaganza.LocalName(el) == st.name
+	}
+	return st.any || el.Name() == st.name
+}
+
+func (st *step) filter(t *tree, elements []int) []int {
+	for _, pr := range st.predicates {
+		if len(elements) == 0 {
+			return nil
+		}
+		switch pr.kind {
+		case positionPredicate:
+			if pr.position > len(elements) {
+				return nil
+			}
+			elements = elements[pr.position-1 : pr.position]
+			continue
+		case lastPredicate:
+			elements = elements[len(elements)-1:]
+			continue
+		}
+		filtered := make([]int, 0, len(elements))
+		for _, pos := range elements {
+			if pr.matches(t.elements[pos]) {
+				filtered = append(filtered, pos)
+			}
+		}
+		elements = filtered
+	}
+	return elements
+}
+
+func (pr *predicate) matches(el stravaganza.Element) bool {
+	switch pr.kind {
+	case hasAttributePredicate:
+		return hasAttribute(el, pr.name)
+	case attributeEqualsPredicate:
+		return hasAttribute(el, pr.name) && el.Attribute(pr.name) == pr.value
+	case attributeNotEqualsPredicate:
+		return hasAttribute(el, pr.name) && el.Attribute(pr.name) != pr.value
+	case textEqualsPredicate:
+		return el.Text() == pr.value
+	}
+	return false
+}
+
+func hasAttribute(el stravaganza.Element, label string) bool {
+	for _, attr := range el.AllAttributes() {
+		if attr.Label == label {
+			return true
+		}
+	}
+	return false
+}
+
+// tree is a pre-order flattened view of an element and all its descendants.
+// Every element occurrence is identified by its walk position, even when the
+// copy-on-write builder shares the same subtree in several places.
+type tree struct {
+	elements []stravaganza.Element
+	ends     []int // position following the last descendant of each element
+}
+
+func newTree(root stravaganza.Element) *tree {
+	t := &tree{}
+	t.walk(root)
+	return t
+}
+
+// walk visits el and all its descendants in document order, assigning them consecutive positions.
+func (t *tree) walk(el stravaganza.Element) {
+	pos := len(t.elements)
+	t.elements = append(t.elements, el)
+	t.ends = append(t.ends, 0)
+	for _, child := range el.AllChildren() {
+		t.walk(child)
+	}
+	t.ends[pos] = len(t.elements)
+}
+
+// children returns the positions of the element at pos direct children.
+func (t *tree) children(pos int) []int {
+	var children []int
+	for child := pos + 1; child < t.ends[pos]; child = t.ends[child] {
+		children = append(children, child)
+	}
+	return children
+}
+
+// inDocumentOrder returns positions sorted in document order, removing any repeated one.
+func inDocumentOrder(positions []int) []int {
+	if len(positions) < 2 {
+		return positions
+	}
+	sort.Ints(positions)
+	ret := positions[:1]
+	for _, pos := range positions[1:] {
+		if pos != ret[len(ret)-1] {
+			ret = append(ret, pos)
+		}
+	}
+	return ret
+}
+
+type exprParser struct {
+	expr string
+	pos  int
+}
+
+func (p *exprParser) parse() (*Selector, error) {
+	s := &Selector{expr: p.expr}
+	if len(strings.TrimSpace(p.expr)) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	descendant := false
+	switch {
+	case strings.HasPrefix(p.expr, "//"):
+		descendant = true
+		p.pos = 2
+	case strings.HasPrefix(p.expr, "/"):
+		s.self = true
+		p.pos = 1
+	}
+	for {
+		if s.result != elementResult {
+			return nil, fmt.Errorf("unexpected step after %s at offset %d", p.resultName(s), p.pos)
+		}
+		switch {
+		case p.consume("text()"):
+			s.result = textResult
+			if descendant || (s.self && len(s.steps) == 0) {
+				return nil, fmt.Errorf("text() must follow an element step")
+			}
+		case p.consume("@"):
+			name := p.name()
+			if len(name) == 0 {
+				return nil, fmt.Errorf("expected attribute name at offset %d", p.pos)
+			}
+			s.result = attributeResult
+			s.attrName = name
+			if descendant || (s.self && len(s.steps) == 0) {
+				return nil, fmt.Errorf("@%s must follow an element step", name)
+			}
+		default:
+			st, err := p.step()
+			if err != nil {
+				return nil, err
+			}
+			st.descendant = descendant
+			s.steps = append(s.steps, st)
+		}
+		if p.eof() {
+			break
+		}
+		switch {
+		case p.consume("//"):
+			descendant = true
+		case p.consume("/"):
+			descendant = false
+		default:
+			return nil, fmt.Errorf("unexpected character %q at offset %d", p.expr[p.pos], p.pos)
+		}
+		if p.eof() {
+			return nil, fmt.Errorf("unexpected end of expression")
+		}
+	}
+	if len(s.steps) == 0 {
+		return nil, fmt.Errorf("expected element step")
+	}
+	return s, nil
+}
+
+func (p *exprParser) step() (step, error) {
+	var st step
+	if p.consume("{") {
+		end := strings.IndexByte(p.expr[p.pos:], '}')
+		if end == -1 {
+			return st, fmt.Errorf("unterminated namespace at offset %d", p.pos)
+		}
+		st.ns = p.expr[p.pos : p.pos+end]
+		st.matchNS = true
+		p.pos += end + 1
+	}
+	if p.consume("*") {
+		st.any = true
+	} else {
+		st.name = p.name()
+		if len(st.name) == 0 {
+			return st, fmt.Errorf("expected element name at offset %d", p.pos)
+		}
+		if st.matchNS && strings.Contains(st.name, ":") {
+			return st, fmt.Errorf("unexpected prefixed name %q along with namespace", st.name)
+		}
+	}
+	for p.consume("[") {
+		pr, err := p.predicate()
+		if err != nil {
+			return st, err
+		}
+		if !p.consume("]") {
+			return st, fmt.Errorf("expected ']' at offset %d", p.pos)
+		}
+		st.predicates = append(st.predicates, pr)
+	}
+	return st, nil
+}
+
+func (p *exprParser) predicate() (predicate, error) {
+	var pr predicate
+	switch {
+	case p.consume("last()"):
+		pr.kind = lastPredicate
+		return pr, nil
+
+	case p.consume("text()"):
+		if !p.consume("=") {
+			return pr, fmt.Errorf("expected '=' at offset %d", p.pos)
+		}
+		value, err := p.literal()
+		if err != nil {
+			return pr, err
+		}
+		pr.kind = textEqualsPredicate
+		pr.value = value
+		return pr, nil
+
+	case p.consume("@"):
+		pr.name = p.name()
+		if len(pr.name) == 0 {
+			return pr, fmt.Errorf("expected attribute name at offset %d", p.pos)
+		}
+		switch {
+		case p.consume("="):
+			pr.kind = attributeEqualsPredicate
+		case p.consume("!="):
+			pr.kind = attributeNotEqualsPredicate
+		default:
+			pr.kind = hasAttributePredicate
+			return pr, nil
+		}
+		value, err := p.literal()
+		if err != nil {
+			return pr, err
+		}
+		pr.value = value
+		return pr, nil
+	}
+	start := p.pos
+	for !p.eof() && p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9' {
+		p.pos++
+	}
+	position, err := strconv.Atoi(p.expr[start:p.pos])
+	if err != nil || position < 1 {
+		return pr, fmt.Errorf("invalid predicate at offset %d", start)
+	}
+	pr.kind = positionPredicate
+	pr.position = position
+	return pr, nil
+}
+
+func (p *exprParser) literal() (string, error) {
+	if p.eof() || (p.expr[p.pos] != '\'' && p.expr[p.pos] != '"') {
+		return "", fmt.Errorf("expected quoted literal at offset %d", p.pos)
+	}
+	quote := p.expr[p.pos]
+	end := strings.IndexByte(p.expr[p.pos+1:], quote)
+	if end == -1 {
+		return "", fmt.Errorf("unterminated literal at offset %d", p.pos)
+	}
+	value := p.expr[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	return value, nil
+}
+
+func (p *exprParser) name() string {
+	start := p.pos
+	for !p.eof() && isNameChar(p.expr[p.pos]) {
+		p.pos++
+	}
+	return p.expr[start:p.pos]
+}
+
+func (p *exprParser) consume(s string) bool {
+	if strings.HasPrefix(p.expr[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) eof() bool {
+	return p.pos >= len(p.expr)
+}
+
+func (p *exprParser) resultName(s *Selector) string {
+	if s.result == textResult {
+		return "text()"
+	}
+	return "@" + s.attrName
+}
+
+func isNameChar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	case c == '-' || c == '_' || c == '.' || c == ':' || c >= 0x80:
+		return true
+	}
+	return false
+}
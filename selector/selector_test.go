@@ -0,0 +1,169 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza"
+	xmppparser "github.com/jackal-xmpp/stravaganza/parser"
+	"github.com/stretchr/testify/require"
+)
+
+const testDoc = `<iq id='1' type='result'>` +
+	`<pubsub xmlns='http://jabber.org/protocol/pubsub'>` +
+	`<items node='princely_musings'>` +
+	`<item id='ae890ac52d0df67ed7cfdf51b644e901'><entry xmlns='http://www.w3.org/2005/Atom'><title>Soliloquy</title></entry></item>` +
+	`<item id='3300659945416e274474e469a1f0154c'><entry xmlns='urn:other'><title>Other</title></entry></item>` +
+	`<item id='4e30f35051b7b8b42abe083742187228'><a:entry xmlns:a='http://www.w3.org/2005/Atom'><a:title>Prefixed</a:title></a:entry></item>` +
+	`</items>` +
+	`</pubsub>` +
+	`</iq>`
+
+func TestSelector_Elements(t *testing.T) {
+	el := testElement(t)
+
+	var tcs = []struct {
+		expr     string
+		expected []string
+	}{
+		{expr: "pubsub/items/item", expected: []string{"item", "item", "item"}},
+		{expr: "pubsub/items/item/entry[@xmlns='http://www.w3.org/2005/Atom']", expected: []string{"entry"}},
+		{expr: "pubsub/items/item/{http://www.w3.org/2005/Atom}entry", expected: []string{"entry", "a:entry"}},
+		{expr: "pubsub/items/item/{http://www.w3.org/2005/Atom}*", expected: []string{"entry", "a:entry"}},
+		{expr: "pubsub/items/item[2]/*", expected: []string{"entry"}},
+		{expr: "pubsub/items/item[last()]/*", expected: []string{"a:entry"}},
+		{expr: "pubsub/items/item[4]", expected: nil},
+		{expr: "pubsub/items/item[@id!='3300659945416e274474e469a1f0154c'][1]/entry", expected: []string{"entry"}},
+		{expr: "//title", expected: []string{"title", "title"}},
+		{expr: "//*[text()='Prefixed']", expected: []string{"a:title"}},
+		{expr: "/iq[@type='result']/pubsub", expected: []string{"pubsub"}},
+		{expr: "/message/pubsub", expected: nil},
+		{expr: "pubsub//item[1]", expected: []string{"item"}},
+		{expr: "pubsub/items[@node]", expected: []string{"items"}},
+		{expr: "pubsub/items[@max_items]", expected: nil},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.expr, func(t *testing.T) {
+			s, err := Compile(tc.expr)
+			require.Nil(t, err)
+
+			var names []string
+			for _, e := range s.Elements(el) {
+				names = append(names, e.Name())
+			}
+			require.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestSelector_ElementsNestedContext(t *testing.T) {
+	p := xmppparser.New(strings.NewReader(`<r><a id='1'><a id='2'><b id='3'/></a><b id='4'/></a></r>`), xmppparser.DefaultMode, 0)
+	el, err := p.Parse()
+	require.Nil(t, err)
+
+	require.Equal(t, []string{"3", "4"}, MustCompile("//a//b/@id").Strings(el))
+	require.Equal(t, []string{"3", "4"}, MustCompile("//b/@id").Strings(el))
+	require.Equal(t, []string{"1", "2"}, MustCompile("//a/@id").Strings(el))
+}
+
+func TestSelector_ElementsSharedSubtree(t *testing.T) {
+	// given
+	item := stravaganza.NewBuilder("item").WithAttribute("id", "1").Build()
+	el := stravaganza.NewBuilder("r").
+		WithChild(stravaganza.NewBuilder("a").WithChild(item).Build()).
+		WithChild(item).
+		Build()
+
+	// when
+	elements := MustCompile("//item").Elements(el)
+
+	// then
+	require.Len(t, elements, 2)
+	require.Equal(t, []string{"1", "1"}, MustCompile("//item/@id").Strings(el))
+}
+
+func TestSelector_Strings(t *testing.T) {
+	el := testElement(t)
+
+	s := MustCompile("pubsub/items/item/@id")
+	require.Equal(t, []string{
+		"ae890ac52d0df67ed7cfdf51b644e901",
+		"3300659945416e274474e469a1f0154c",
+		"4e30f35051b7b8b42abe083742187228",
+	}, s.Strings(el))
+	require.Equal(t, "ae890ac52d0df67ed7cfdf51b644e901", s.Value(el))
+	require.Len(t, s.Elements(el), 3)
+
+	s = MustCompile("//{http://www.w3.org/2005/Atom}entry/*/text()")
+	require.Equal(t, []string{"Soliloquy", "Prefixed"}, s.Strings(el))
+
+	s = MustCompile("pubsub/items/item/entry/title")
+	require.Equal(t, "Soliloquy", s.Value(el))
+	require.Equal(t, "title", s.First(el).Name())
+
+	s = MustCompile("pubsub/configure/@node")
+	require.Nil(t, s.Strings(el))
+	require.Equal(t, "", s.Value(el))
+	require.Nil(t, s.First(el))
+}
+
+func TestSelector_CompileErrors(t *testing.T) {
+	exprs := []string{
+		"",
+		"a/",
+		"a//",
+		"a[",
+		"a[0]",
+		"a[@b='c]",
+		"a[@]",
+		"{urn:x",
+		"{urn:x}p:a",
+		"a/text()/b",
+		"a/@id/b",
+		"/text()",
+		"@id",
+		"a b",
+	}
+	for _, expr := range exprs {
+		_, err := Compile(expr)
+		require.NotNil(t, err, expr)
+	}
+}
+
+func TestSelector_String(t *testing.T) {
+	s := MustCompile("pubsub/items/item")
+	require.Equal(t, "pubsub/items/item", s.String())
+}
+
+func testElement(t *testing.T) stravaganza.Element {
+	p := xmppparser.New(strings.NewReader(testDoc), xmppparser.DefaultMode, 0, xmppparser.WithNamespaceResolution())
+	el, err := p.Parse()
+	require.Nil(t, err)
+	return el
+}
+
+func BenchmarkSelector_Value(b *testing.B) {
+	p := xmppparser.New(strings.NewReader(testDoc), xmppparser.DefaultMode, 0)
+	el, _ := p.Parse()
+	s := MustCompile("pubsub/items/item/entry[@xmlns='http://www.w3.org/2005/Atom']/title")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = s.Value(el)
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package xmppsm provides stream management elements and stanza acknowledgement bookkeeping,
+// as defined in XEP-0198.
+package xmppsm
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jackal-xmpp/stravaganza"
+	stanzaerror "github.com/jackal-xmpp/stravaganza/errors/stanza"
+)
+
+// Namespace is the stream management namespace.
+const Namespace = "urn:xmpp:sm:3"
+
+// Element represents a stream management element.
+type Element interface {
+	// Element returns the XML representation of the stream management element.
+	Element() stravaganza.Element
+}
+
+// Enable represents an <enable/> element.
+type Enable struct {
+	// Resume tells whether the entity requests session resumption.
+	Resume bool
+
+	// Max is the preferred maximum resumption time in seconds. Zero means no preference.
+	Max uint32
+}
+
+// Element satisfies Element interface.
+func (e *Enable) Element() stravaganza.Element {
+	b := newBuilder("enable")
+	if e.Resume {
+		b.WithAttribute("resume", "true")
+	}
+	if e.Max > 0 {
+		b.WithAttribute("max", strconv.FormatUint(uint64(e.Max), 10))
+	}
+	return b.Build()
+}
+
+// Enabled represents an <enabled/> element.
+type Enabled struct {
+	// ID is the stream management identifier, used on resumption.
+	ID string
+
+	// Resume tells whether session resumption is allowed.
+	Resume bool
+
+	// Max is the maximum resumption time in seconds. Zero means not specified.
+	Max uint32
+
+	// Location is the preferred IP address or hostname, optionally with a port, to use on resumption.
+	Location string
+}
+
+// Element satisfies Element interface.
+func (e *Enabled) Element() stravaganza.Element {
+	b := newBuilder("enabled")
+	if len(e.ID) > 0 {
+		b.WithAttribute("id", e.ID)
+	}
+	if e.Resume {
+		b.WithAttribute("resume", "true")
+	}
+	if e.Max > 0 {
+		b.WithAttribute("max", strconv.FormatUint(uint64(e.Max), 10))
+	}
+	if len(e.Location) > 0 {
+		b.WithAttribute("location", e.Location)
+	}
+	return b.Build()
+}
+
+// Request represents an <r/> acknowledgement request element.
+type Request struct{}
+
+// Element satisfies Element interface.
+func (r *Request) Element() stravaganza.Element {
+	return newBuilder("r").Build()
+}
+
+// Ack represents an <a/> acknowledgement element.
+type Ack struct {
+	// H is the number of handled stanzas.
+	H uint32
+}
+
+// Element satisfies Element interface.
+func (a *Ack) Element() stravaganza.Element {
+	return newBuilder("a").
+		WithAttribute("h", formatH(a.H)).
+		Build()
+}
+
+// Resume represents a <resume/> element.
+type Resume struct {
+	// H is the number of stanzas handled by the resuming entity.
+	H uint32
+
+	// PrevID is the stream management identifier of the resumed session.
+	PrevID string
+}
+
+// Element satisfies Element interface.
+func (r *Resume) Element() stravaganza.Element {
+	return newBuilder("resume").
+		WithAttribute("h", formatH(r.H)).
+		WithAttribute("previd", r.PrevID).
+		Build()
+}
+
+// Resumed represents a <resumed/> element.
+type Resumed struct {
+	// H is the number of stanzas handled by the receiving entity.
+	H uint32
+
+	// PrevID is the stream management identifier of the resumed session.
+	PrevID string
+}
+
+// Element satisfies Element interface.
+func (r *Resumed) Element() stravaganza.Element {
+	return newBuilder("resumed").
+		WithAttribute("h", formatH(r.H)).
+		WithAttribute("previd", r.PrevID).
+		Build()
+}
+
+// Failed represents a <failed/> element.
+type Failed struct {
+	// Reason is the failure condition, expressed as a stanza error condition.
+	Reason stanzaerror.Reason
+
+	// H is the number of stanzas handled by the entity. Only meaningful when HasH is true.
+	H uint32
+
+	// HasH tells whether the failure includes the number of handled stanzas.
+	HasH bool
+
+	// Text is the failure descriptive text.
+	Text string
+}
+
+// Element satisfies Element interface.
+func (f *Failed) Element() stravaganza.Element {
+	b := newBuilder("failed")
+	if f.HasH {
+		b.WithAttribute("h", formatH(f.H))
+	}
+	b.WithChild(
+		stravaganza.NewBuilder(f.Reason.String()).
+			WithAttribute(stravaganza.Namespace, stanzaerror.Namespace).
+			Build(),
+	)
+	if len(f.Text) > 0 {
+		b.WithChild(
+			stravaganza.NewBuilder("text").
+				WithAttribute(stravaganza.Namespace, stanzaerror.Namespace).
+				WithText(f.Text).
+				Build(),
+		)
+	}
+	return b.Build()
+}
+
+// Parse parses a stream management element.
+func Parse(el stravaganza.Element) (Element, error) {
+	if el == nil || stravaganza.NamespaceURI(el) != Namespace {
+		return nil, fmt.Errorf("xmppsm: not a stream management element")
+	}
+	switch stravaganza.LocalName(el) {
+	case "enable":
+		max, err := parseMax(el)
+		if err != nil {
+			return nil, err
+		}
+		return &Enable{Resume: parseBool(el.Attribute("resume")), Max: max}, nil
+
+	case "enabled":
+		max, err := parseMax(el)
+		if err != nil {
+			return nil, err
+		}
+		return &Enabled{
+			ID:       el.Attribute("id"),
+			Resume:   parseBool(el.Attribute("resume")),
+			Max:      max,
+			Location: el.Attribute("location"),
+		}, nil
+
+	case "r":
+		return &Request{}, nil
+
+	case "a":
+		h, err := parseH(el.Attribute("h"))
+		if err != nil {
+			return nil, err
+		}
+		return &Ack{H: h}, nil
+
+	case "resume", "resumed":
+		h, err := parseH(el.Attribute("h"))
+		if err != nil {
+			return nil, err
+		}
+		prevID := el.Attribute("previd")
+		if len(prevID) == 0 {
+			return nil, fmt.Errorf("xmppsm: missing %s 'previd' attribute", stravaganza.LocalName(el))
+		}
+		if stravaganza.LocalName(el) == "resume" {
+			return &Resume{H: h, PrevID: prevID}, nil
+		}
+		return &Resumed{H: h, PrevID: prevID}, nil
+
+	case "failed":
+		return parseFailed(el)
+	}
+	return nil, fmt.Errorf("xmppsm: unexpected element: %s", stravaganza.LocalName(el))
+}
+
+func parseFailed(el stravaganza.Element) (*Failed, error) {
+	f := &Failed{}
+	if hAttr := el.Attribute("h"); len(hAttr) > 0 {
+		h, err := parseH(hAttr)
+		if err != nil {
+			return nil, err
+		}
+		f.H = h
+		f.HasH = true
+	}
+	// failed element content is made of stanza error conditions
+	f.Reason = stanzaerror.UndefinedCondition
+	for _, child := range el.AllChildren() {
+		if stravaganza.NamespaceURI(child) != stanzaerror.Namespace {
+			continue
+		}
+		if stravaganza.LocalName(child) == "text" {
+			f.Text = child.Text()
+		} else if reason, ok := stanzaerror.ParseReason(stravaganza.LocalName(child)); ok {
+			f.Reason = reason
+		}
+	}
+	return f, nil
+}
+
+func newBuilder(name string) *stravaganza.Builder {
+	return stravaganza.NewBuilder(name).
+		WithAttribute(stravaganza.Namespace, Namespace)
+}
+
+func formatH(h uint32) string {
+	return strconv.FormatUint(uint64(h), 10)
+}
+
+func parseH(s string) (uint32, error) {
+	h, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("xmppsm: invalid 'h' attribute: %q", s)
+	}
+	return uint32(h), nil
+}
+
+func parseMax(el stravaganza.Element) (uint32, error) {
+	s := el.Attribute("max")
+	if len(s) == 0 {
+		return 0, nil
+	}
+	max, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("xmppsm: invalid 'max' attribute: %q", s)
+	}
+	return uint32(max), nil
+}
+
+func parseBool(s string) bool {
+	return s == "true" || s == "1"
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppsm
+
+import (
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza"
+	stanzaerror "github.com/jackal-xmpp/stravaganza/errors/stanza"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElements_ToXML(t *testing.T) {
+	var tcs = []struct {
+		name     string
+		el       Element
+		expected string
+	}{
+		{
+			name:     "Enable",
+			el:       &Enable{Resume: true, Max: 300},
+			expected: `<enable xmlns='urn:xmpp:sm:3' resume='true' max='300'/>`,
+		},
+		{
+			name:     "Enabled",
+			el:       &Enabled{ID: "some-long-sm-id", Resume: true, Location: "[2001:41D0:1:A49b::1]:9222"},
+			expected: `<enabled xmlns='urn:xmpp:sm:3' id='some-long-sm-id' resume='true' location='[2001:41D0:1:A49b::1]:9222'/>`,
+		},
+		{
+			name:     "Request",
+			el:       &Request{},
+			expected: `<r xmlns='urn:xmpp:sm:3'/>`,
+		},
+		{
+			name:     "Ack",
+			el:       &Ack{H: 4294967295},
+			expected: `<a xmlns='urn:xmpp:sm:3' h='4294967295'/>`,
+		},
+		{
+			name:     "Resume",
+			el:       &Resume{H: 0, PrevID: "some-long-sm-id"},
+			expected: `<resume xmlns='urn:xmpp:sm:3' h='0' previd='some-long-sm-id'/>`,
+		},
+		{
+			name:     "Resumed",
+			el:       &Resumed{H: 12, PrevID: "some-long-sm-id"},
+			expected: `<resumed xmlns='urn:xmpp:sm:3' h='12' previd='some-long-sm-id'/>`,
+		},
+		{
+			name:     "Failed",
+			el:       &Failed{Reason: stanzaerror.ItemNotFound, H: 2, HasH: true},
+			expected: `<failed xmlns='urn:xmpp:sm:3' h='2'><item-not-found xmlns='urn:ietf:params:xml:ns:xmpp-stanzas'/></failed>`,
+		},
+		{
+			name:     "FailedText",
+			el:       &Failed{Reason: stanzaerror.UnexpectedRequest, Text: "already enabled"},
+			expected: `<failed xmlns='urn:xmpp:sm:3'><unexpected-request xmlns='urn:ietf:params:xml:ns:xmpp-stanzas'/><text xmlns='urn:ietf:params:xml:ns:xmpp-stanzas'>already enabled</text></failed>`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			el := tc.el.Element()
+			require.Equal(t, tc.expected, el.String())
+
+			parsed, err := Parse(el)
+			require.Nil(t, err)
+			require.Equal(t, tc.el, parsed)
+		})
+	}
+}
+
+func TestElements_ParseErrors(t *testing.T) {
+	var tcs = []struct {
+		name string
+		el   stravaganza.Element
+	}{
+		{name: "WrongNamespace", el: stravaganza.NewBuilder("r").WithAttribute(stravaganza.Namespace, "urn:xmpp:sm:2").Build()},
+		{name: "UnknownElement", el: newBuilder("x").Build()},
+		{name: "MissingH", el: newBuilder("a").Build()},
+		{name: "NegativeH", el: newBuilder("a").WithAttribute("h", "-1").Build()},
+		{name: "OverflowH", el: newBuilder("a").WithAttribute("h", "4294967296").Build()},
+		{name: "MissingPrevID", el: newBuilder("resume").WithAttribute("h", "1").Build()},
+		{name: "InvalidMax", el: newBuilder("enable").WithAttribute("max", "x").Build()},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.el)
+			require.NotNil(t, err)
+		})
+	}
+}
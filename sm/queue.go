@@ -0,0 +1,211 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppsm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jackal-xmpp/stravaganza"
+)
+
+const (
+	defaultAckRequestThreshold = 5
+	defaultMaxQueueSize        = 1000
+)
+
+var (
+	// ErrQueueFull will be returned by Push when the maximum number of unacknowledged stanzas has been reached.
+	ErrQueueFull = errors.New("xmppsm: queue full")
+
+	// ErrHandledCountDecreased will be returned when the peer acknowledges fewer stanzas than the already
+	// acknowledged ones.
+	ErrHandledCountDecreased = errors.New("xmppsm: handled count decreased")
+)
+
+// HandledCountTooHighError will be returned when the peer acknowledges more stanzas than the ones sent.
+// As stated in XEP-0198 section 4, it should be reported by means of an undefined-condition stream error.
+type HandledCountTooHighError struct {
+	// H is the acknowledged handled count.
+	H uint32
+
+	// SendCount is the number of sent stanzas.
+	SendCount uint32
+}
+
+// Error satisfies error interface.
+func (e *HandledCountTooHighError) Error() string {
+	return fmt.Sprintf("xmppsm: handled count too high: h=%d, send-count=%d", e.H, e.SendCount)
+}
+
+// Element returns the <handled-count-too-high/> stream error application element.
+func (e *HandledCountTooHighError) Element() stravaganza.Element {
+	return stravaganza.NewBuilder("handled-count-too-high").
+		WithAttribute(stravaganza.Namespace, Namespace).
+		WithAttribute("h", formatH(e.H)).
+		WithAttribute("send-count", formatH(e.SendCount)).
+		Build()
+}
+
+// QueueOption defines a queue option.
+type QueueOption func(*Queue)
+
+// WithAckRequestThreshold sets the number of stanzas to be sent before requesting an acknowledgement.
+func WithAckRequestThreshold(n int) QueueOption {
+	return func(q *Queue) {
+		q.ackRequestThreshold = n
+	}
+}
+
+// WithMaxQueueSize sets the maximum number of unacknowledged stanzas.
+func WithMaxQueueSize(n int) QueueOption {
+	return func(q *Queue) {
+		q.maxSize = n
+	}
+}
+
+// Queue keeps track of stream management counters and of sent stanzas pending to be acknowledged.
+// Counters are kept as uint32 values, so they wrap around to zero after 2^32-1, as required by XEP-0198.
+// It's safe to use from multiple goroutines.
+type Queue struct {
+	ackRequestThreshold int
+	maxSize             int
+
+	mu           sync.Mutex
+	outboundH    uint32
+	inboundH     uint32
+	pending      []stravaganza.Stanza
+	sinceRequest int
+}
+
+// NewQueue returns a new initialized stream management queue.
+func NewQueue(opts ...QueueOption) *Queue {
+	q := &Queue{
+		ackRequestThreshold: defaultAckRequestThreshold,
+		maxSize:             defaultMaxQueueSize,
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// Push enqueues a sent stanza until it gets acknowledged.
+// The returned flag tells whether an acknowledgement request should be sent to the peer
+// according to the queue ack request policy.
+func (q *Queue) Push(stanza stravaganza.Stanza) (requestAck bool, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.maxSize > 0 && len(q.pending) >= q.maxSize {
+		return false, ErrQueueFull
+	}
+	q.pending = append(q.pending, stanza)
+	q.outboundH++
+	q.sinceRequest++
+
+	if q.sinceRequest >= q.ackRequestThreshold {
+		q.sinceRequest = 0
+		return true, nil
+	}
+	return false, nil
+}
+
+// Ack processes a peer acknowledgement, discarding all stanzas handled by the peer.
+func (q *Queue) Ack(h uint32) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.ack(h)
+}
+
+// Resume processes the peer handled count received on resumption, returning
+// the stanzas to be resent. Resent stanzas remain pending to be acknowledged.
+func (q *Queue) Resume(h uint32) ([]stravaganza.Stanza, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.ack(h); err != nil {
+		return nil, err
+	}
+	q.sinceRequest = 0
+	return q.unacknowledged(), nil
+}
+
+// Handled increments the number of handled inbound stanzas, returning the updated count.
+func (q *Queue) Handled() uint32 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.inboundH++
+	return q.inboundH
+}
+
+// InboundH returns the number of handled inbound stanzas, as reported to the peer in <a/> and <resumed/> elements.
+func (q *Queue) InboundH() uint32 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.inboundH
+}
+
+// OutboundH returns the number of sent stanzas.
+func (q *Queue) OutboundH() uint32 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.outboundH
+}
+
+// Unacknowledged returns the sent stanzas pending to be acknowledged, in sending order.
+func (q *Queue) Unacknowledged() []stravaganza.Stanza {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.unacknowledged()
+}
+
+// Len returns the number of stanzas pending to be acknowledged.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+func (q *Queue) ack(h uint32) error {
+	// modular arithmetic takes care of counters wraparound
+	lastAcked := q.outboundH - uint32(len(q.pending))
+	if h-lastAcked > uint32(len(q.pending)) {
+		if lastAcked-h < 1<<31 {
+			// h lies behind the last acknowledged count
+			return fmt.Errorf("%w: h=%d, last-h=%d", ErrHandledCountDecreased, h, lastAcked)
+		}
+		return &HandledCountTooHighError{H: h, SendCount: q.outboundH}
+	}
+	acked := int(h - lastAcked)
+	for i := 0; i < acked; i++ {
+		q.pending[i] = nil // release references
+	}
+	q.pending = q.pending[acked:]
+	if len(q.pending) == 0 {
+		q.pending = nil
+	}
+	return nil
+}
+
+func (q *Queue) unacknowledged() []stravaganza.Stanza {
+	if len(q.pending) == 0 {
+		return nil
+	}
+	stanzas := make([]stravaganza.Stanza, len(q.pending))
+	copy(stanzas, q.pending)
+	return stanzas
+}
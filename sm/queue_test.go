@@ -0,0 +1,202 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppsm
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_Ack(t *testing.T) {
+	// given
+	q := NewQueue()
+	for i := 0; i < 4; i++ {
+		_, _ = q.Push(testMessage(t, i))
+	}
+
+	// when
+	err := q.Ack(3)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, 1, q.Len())
+	require.Equal(t, uint32(4), q.OutboundH())
+	require.Equal(t, "3", q.Unacknowledged()[0].ID())
+
+	require.Nil(t, q.Ack(3)) // repeated ack
+	require.Nil(t, q.Ack(4))
+	require.Equal(t, 0, q.Len())
+	require.Nil(t, q.Unacknowledged())
+}
+
+func TestQueue_HandledCountTooHigh(t *testing.T) {
+	// given
+	q := NewQueue()
+	_, _ = q.Push(testMessage(t, 0))
+	_, _ = q.Push(testMessage(t, 1))
+
+	// when
+	err := q.Ack(3)
+
+	// then
+	var hErr *HandledCountTooHighError
+	require.True(t, errors.As(err, &hErr))
+	require.Equal(t, uint32(3), hErr.H)
+	require.Equal(t, uint32(2), hErr.SendCount)
+	require.Equal(t, `<handled-count-too-high xmlns='urn:xmpp:sm:3' h='3' send-count='2'/>`, hErr.Element().String())
+	require.Equal(t, 2, q.Len())
+}
+
+func TestQueue_HandledCountDecreased(t *testing.T) {
+	// given
+	q := NewQueue()
+	for i := 0; i < 4; i++ {
+		_, _ = q.Push(testMessage(t, i))
+	}
+	_ = q.Ack(3)
+
+	// when
+	err := q.Ack(1)
+
+	// then
+	require.True(t, errors.Is(err, ErrHandledCountDecreased))
+	require.Equal(t, "xmppsm: handled count decreased: h=1, last-h=3", err.Error())
+
+	var hErr *HandledCountTooHighError
+	require.False(t, errors.As(err, &hErr))
+	require.Equal(t, 1, q.Len())
+}
+
+func TestQueue_Wraparound(t *testing.T) {
+	// given
+	q := NewQueue()
+	q.outboundH = math.MaxUint32 - 1
+	q.inboundH = math.MaxUint32
+
+	for i := 0; i < 4; i++ {
+		_, _ = q.Push(testMessage(t, i))
+	}
+
+	// when
+	err1 := q.Ack(math.MaxUint32)
+	err2 := q.Ack(1)
+	inboundH := q.Handled()
+
+	// then
+	require.Nil(t, err1)
+	require.Nil(t, err2)
+	require.Equal(t, uint32(2), q.OutboundH())
+	require.Equal(t, 1, q.Len())
+	require.Equal(t, "3", q.Unacknowledged()[0].ID())
+	require.Equal(t, uint32(0), inboundH)
+
+	var hErr *HandledCountTooHighError
+	require.True(t, errors.As(q.Ack(3), &hErr))
+	require.True(t, errors.Is(q.Ack(math.MaxUint32), ErrHandledCountDecreased))
+}
+
+func TestQueue_AckRequestPolicy(t *testing.T) {
+	// given
+	q := NewQueue(WithAckRequestThreshold(3))
+
+	// when
+	var requests []bool
+	for i := 0; i < 7; i++ {
+		requestAck, err := q.Push(testMessage(t, i))
+		require.Nil(t, err)
+		requests = append(requests, requestAck)
+	}
+
+	// then
+	require.Equal(t, []bool{false, false, true, false, false, true, false}, requests)
+}
+
+func TestQueue_Full(t *testing.T) {
+	// given
+	q := NewQueue(WithMaxQueueSize(2))
+	_, _ = q.Push(testMessage(t, 0))
+	_, _ = q.Push(testMessage(t, 1))
+
+	// when
+	_, err := q.Push(testMessage(t, 2))
+
+	// then
+	require.Equal(t, ErrQueueFull, err)
+	require.Nil(t, q.Ack(1))
+
+	_, err = q.Push(testMessage(t, 2))
+	require.Nil(t, err)
+}
+
+func TestQueue_Resume(t *testing.T) {
+	// given
+	q := NewQueue()
+	for i := 0; i < 5; i++ {
+		_, _ = q.Push(testMessage(t, i))
+	}
+	_ = q.Ack(1)
+
+	// when
+	stanzas, err := q.Resume(3)
+
+	// then
+	require.Nil(t, err)
+	require.Len(t, stanzas, 2)
+	require.Equal(t, "3", stanzas[0].ID())
+	require.Equal(t, "4", stanzas[1].ID())
+	require.Equal(t, 2, q.Len())
+	require.Equal(t, uint32(5), q.OutboundH())
+
+	_, err = q.Resume(6)
+	require.NotNil(t, err)
+}
+
+func TestQueue_Concurrent(t *testing.T) {
+	q := NewQueue(WithMaxQueueSize(0))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, _ = q.Push(testMessage(t, j))
+				q.Handled()
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, uint32(800), q.OutboundH())
+	require.Equal(t, uint32(800), q.InboundH())
+	require.Nil(t, q.Ack(800))
+	require.Equal(t, 0, q.Len())
+}
+
+func testMessage(t *testing.T, i int) stravaganza.Stanza {
+	msg, err := stravaganza.NewMessageBuilder().
+		WithAttribute(stravaganza.ID, strconv.Itoa(i)).
+		WithAttribute(stravaganza.From, "romeo@example.net/orchard").
+		WithAttribute(stravaganza.To, "juliet@example.com/balcony").
+		BuildMessage()
+	require.Nil(t, err)
+	return msg
+}
@@ -1,17 +1,26 @@
+// Copyright 2020 The jackal Authors
 //
-// Copyright (c) 2020 Miguel Ángel Ortuño.
-// See the LICENSE file for more information.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
 
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.24.0-devel
-// 	protoc        v3.13.0
+// 	protoc-gen-go v1.28.0
+// 	protoc        (unknown)
 // source: stravaganza.proto
 
 package stravaganza
 
 import (
-	proto "github.com/golang/protobuf/proto"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -25,10 +34,6 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-// This is a compile-time assertion that a sufficiently up-to-date version
-// of the legacy proto package is being used.
-const _ = proto.ProtoPackageIsVersion4
-
 type PBAttribute struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -84,6 +89,63 @@ func (x *PBAttribute) GetValue() string {
 	return ""
 }
 
+// PBTextNode represents an element text node.
+type PBTextNode struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Number of sub elements preceding the text node.
+	Position uint32 `protobuf:"varint,1,opt,name=position,proto3" json:"position,omitempty"`
+	Value    string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *PBTextNode) Reset() {
+	*x = PBTextNode{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stravaganza_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PBTextNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PBTextNode) ProtoMessage() {}
+
+func (x *PBTextNode) ProtoReflect() protoreflect.Message {
+	mi := &file_stravaganza_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PBTextNode.ProtoReflect.Descriptor instead.
+func (*PBTextNode) Descriptor() ([]byte, []int) {
+	return file_stravaganza_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PBTextNode) GetPosition() uint32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *PBTextNode) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
 type PBElement struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -93,12 +155,16 @@ type PBElement struct {
 	Attributes []*PBAttribute `protobuf:"bytes,2,rep,name=attributes,proto3" json:"attributes,omitempty"`
 	Elements   []*PBElement   `protobuf:"bytes,3,rep,name=elements,proto3" json:"elements,omitempty"`
 	Text       string         `protobuf:"bytes,4,opt,name=text,proto3" json:"text,omitempty"`
+	// Resolved namespace URI, only set by namespace aware parsers.
+	Namespace string `protobuf:"bytes,5,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Ordered text nodes, only set when element text is interleaved with its sub elements.
+	TextNodes []*PBTextNode `protobuf:"bytes,6,rep,name=text_nodes,json=textNodes,proto3" json:"text_nodes,omitempty"`
 }
 
 func (x *PBElement) Reset() {
 	*x = PBElement{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_stravaganza_proto_msgTypes[1]
+		mi := &file_stravaganza_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -111,7 +177,7 @@ func (x *PBElement) String() string {
 func (*PBElement) ProtoMessage() {}
 
 func (x *PBElement) ProtoReflect() protoreflect.Message {
-	mi := &file_stravaganza_proto_msgTypes[1]
+	mi := &file_stravaganza_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -124,7 +190,7 @@ func (x *PBElement) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PBElement.ProtoReflect.Descriptor instead.
 func (*PBElement) Descriptor() ([]byte, []int) {
-	return file_stravaganza_proto_rawDescGZIP(), []int{1}
+	return file_stravaganza_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *PBElement) GetName() string {
@@ -155,6 +221,67 @@ func (x *PBElement) GetText() string {
 	return ""
 }
 
+func (x *PBElement) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *PBElement) GetTextNodes() []*PBTextNode {
+	if x != nil {
+		return x.TextNodes
+	}
+	return nil
+}
+
+type PBElements struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Elements []*PBElement `protobuf:"bytes,1,rep,name=elements,proto3" json:"elements,omitempty"`
+}
+
+func (x *PBElements) Reset() {
+	*x = PBElements{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stravaganza_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PBElements) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PBElements) ProtoMessage() {}
+
+func (x *PBElements) ProtoReflect() protoreflect.Message {
+	mi := &file_stravaganza_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PBElements.ProtoReflect.Descriptor instead.
+func (*PBElements) Descriptor() ([]byte, []int) {
+	return file_stravaganza_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PBElements) GetElements() []*PBElement {
+	if x != nil {
+		return x.Elements
+	}
+	return nil
+}
+
 var File_stravaganza_proto protoreflect.FileDescriptor
 
 var file_stravaganza_proto_rawDesc = []byte{
@@ -163,7 +290,11 @@ var file_stravaganza_proto_rawDesc = []byte{
 	0x22, 0x39, 0x0a, 0x0b, 0x50, 0x42, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x12,
 	0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
 	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0xa1, 0x01, 0x0a, 0x09,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x3e, 0x0a, 0x0a, 0x50,
+	0x42, 0x54, 0x65, 0x78, 0x74, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x73,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x6f, 0x73,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0xf7, 0x01, 0x0a, 0x09,
 	0x50, 0x42, 0x45, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
 	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x38, 0x0a,
 	0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
@@ -173,11 +304,21 @@ var file_stravaganza_proto_rawDesc = []byte{
 	0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x73, 0x74, 0x72, 0x61,
 	0x76, 0x61, 0x67, 0x61, 0x6e, 0x7a, 0x61, 0x2e, 0x50, 0x42, 0x45, 0x6c, 0x65, 0x6d, 0x65, 0x6e,
 	0x74, 0x52, 0x08, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74,
-	0x65, 0x78, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x42,
-	0x30, 0x5a, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6a, 0x61,
-	0x63, 0x6b, 0x61, 0x6c, 0x2d, 0x78, 0x6d, 0x70, 0x70, 0x2f, 0x73, 0x74, 0x72, 0x61, 0x76, 0x61,
-	0x67, 0x61, 0x6e, 0x7a, 0x61, 0x3b, 0x73, 0x74, 0x72, 0x61, 0x76, 0x61, 0x67, 0x61, 0x6e, 0x7a,
-	0x61, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x65, 0x78, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12,
+	0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x36, 0x0a,
+	0x0a, 0x74, 0x65, 0x78, 0x74, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x17, 0x2e, 0x73, 0x74, 0x72, 0x61, 0x76, 0x61, 0x67, 0x61, 0x6e, 0x7a, 0x61, 0x2e,
+	0x50, 0x42, 0x54, 0x65, 0x78, 0x74, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x09, 0x74, 0x65, 0x78, 0x74,
+	0x4e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x40, 0x0a, 0x0a, 0x50, 0x42, 0x45, 0x6c, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x73, 0x12, 0x32, 0x0a, 0x08, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x73, 0x74, 0x72, 0x61, 0x76, 0x61, 0x67, 0x61,
+	0x6e, 0x7a, 0x61, 0x2e, 0x50, 0x42, 0x45, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x08, 0x65,
+	0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x42, 0x30, 0x5a, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6a, 0x61, 0x63, 0x6b, 0x61, 0x6c, 0x2d, 0x78, 0x6d, 0x70,
+	0x70, 0x2f, 0x73, 0x74, 0x72, 0x61, 0x76, 0x61, 0x67, 0x61, 0x6e, 0x7a, 0x61, 0x3b, 0x73, 0x74,
+	0x72, 0x61, 0x76, 0x61, 0x67, 0x61, 0x6e, 0x7a, 0x61, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -192,19 +333,23 @@ func file_stravaganza_proto_rawDescGZIP() []byte {
 	return file_stravaganza_proto_rawDescData
 }
 
-var file_stravaganza_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_stravaganza_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_stravaganza_proto_goTypes = []interface{}{
 	(*PBAttribute)(nil), // 0: stravaganza.PBAttribute
-	(*PBElement)(nil),   // 1: stravaganza.PBElement
+	(*PBTextNode)(nil),  // 1: stravaganza.PBTextNode
+	(*PBElement)(nil),   // 2: stravaganza.PBElement
+	(*PBElements)(nil),  // 3: stravaganza.PBElements
 }
 var file_stravaganza_proto_depIdxs = []int32{
 	0, // 0: stravaganza.PBElement.attributes:type_name -> stravaganza.PBAttribute
-	1, // 1: stravaganza.PBElement.elements:type_name -> stravaganza.PBElement
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	2, // 1: stravaganza.PBElement.elements:type_name -> stravaganza.PBElement
+	1, // 2: stravaganza.PBElement.text_nodes:type_name -> stravaganza.PBTextNode
+	2, // 3: stravaganza.PBElements.elements:type_name -> stravaganza.PBElement
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_stravaganza_proto_init() }
@@ -226,6 +371,18 @@ func file_stravaganza_proto_init() {
 			}
 		}
 		file_stravaganza_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PBTextNode); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stravaganza_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*PBElement); i {
 			case 0:
 				return &v.state
@@ -237,6 +394,18 @@ func file_stravaganza_proto_init() {
 				return nil
 			}
 		}
+		file_stravaganza_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PBElements); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -244,7 +413,7 @@ func file_stravaganza_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_stravaganza_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
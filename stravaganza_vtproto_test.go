@@ -0,0 +1,157 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stravaganza
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPBElement_MarshalVT(t *testing.T) {
+	// given
+	pb := testLargeStanza().Proto()
+
+	// when
+	b, err := pb.MarshalVT()
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, proto.Size(pb), pb.SizeVT())
+
+	var pb2 PBElement
+	require.Nil(t, proto.Unmarshal(b, &pb2))
+	require.True(t, proto.Equal(pb, &pb2))
+}
+
+func TestPBElement_UnmarshalVT(t *testing.T) {
+	// given
+	pb := testLargeStanza().Proto()
+	b, _ := proto.Marshal(pb)
+
+	// when
+	var pb2 PBElement
+	err := pb2.UnmarshalVT(b)
+
+	// then
+	require.Nil(t, err)
+	require.True(t, proto.Equal(pb, &pb2))
+}
+
+func TestPBElement_UnmarshalVTTruncated(t *testing.T) {
+	b, _ := testCodecElement().Proto().MarshalVT()
+	for _, n := range []int{1, 2, len(b) - 1} {
+		var pb PBElement
+		require.NotNil(t, pb.UnmarshalVT(b[:n]), n)
+	}
+}
+
+func TestPBElement_UnmarshalVTUnknownFields(t *testing.T) {
+	// given
+	b, _ := (&PBElement{Name: "message"}).MarshalVT()
+	b = append(b, 0xf8, 0x01, 0x2a) // field 31, varint 42
+
+	// when
+	var pb PBElement
+	err := pb.UnmarshalVT(b)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, "message", pb.Name)
+
+	b2, _ := pb.MarshalVT()
+	require.Equal(t, b, b2)
+}
+
+func TestPBElement_VTPool(t *testing.T) {
+	// given
+	pb := PBElementFromVTPool()
+	b, _ := testCodecElement().Proto().MarshalVT()
+	require.Nil(t, pb.UnmarshalVT(b))
+
+	// when
+	pb.ReturnToVTPool()
+
+	// then
+	require.Equal(t, "", pb.Name)
+	require.Len(t, pb.Attributes, 0)
+	require.Len(t, pb.Elements, 0)
+}
+
+func testLargeStanza() Element {
+	b := NewMessageBuilder().
+		WithAttribute("id", "1234").
+		WithAttribute("type", "groupchat").
+		WithAttribute("from", "coven@chat.shakespeare.lit/thirdwitch").
+		WithAttribute("to", "crone1@shakespeare.lit/desktop")
+	for i := 0; i < 64; i++ {
+		b.WithChild(
+			NewBuilder("item").
+				WithAttribute("id", strconv.Itoa(i)).
+				WithAttribute(xmlNamespace, "urn:xmpp:example").
+				WithChild(NewBuilder("body").WithText("Thrice the brinded cat hath mew'd.").Build()).
+				AppendText("and once the hedge-pig whined").
+				Build(),
+		)
+	}
+	return b.Build()
+}
+
+func BenchmarkPBElement_MarshalVT(b *testing.B) {
+	pb := testLargeStanza().Proto()
+	buf := make([]byte, 0, pb.SizeVT())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = buf[:pb.SizeVT()]
+		_, _ = pb.MarshalToSizedBufferVT(buf)
+	}
+}
+
+func BenchmarkPBElement_ProtoMarshal(b *testing.B) {
+	pb := testLargeStanza().Proto()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = proto.Marshal(pb)
+	}
+}
+
+func BenchmarkPBElement_UnmarshalVT(b *testing.B) {
+	data, _ := testLargeStanza().Proto().MarshalVT()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pb := PBElementFromVTPool()
+		_ = pb.UnmarshalVT(data)
+		pb.ReturnToVTPool()
+	}
+}
+
+func BenchmarkPBElement_ProtoUnmarshal(b *testing.B) {
+	data, _ := testLargeStanza().Proto().MarshalVT()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var pb PBElement
+		_ = proto.Unmarshal(data, &pb)
+	}
+}
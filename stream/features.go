@@ -0,0 +1,190 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppstream
+
+import (
+	"errors"
+
+	"github.com/jackal-xmpp/stravaganza"
+)
+
+const (
+	// TLSNamespace is the STARTTLS feature namespace.
+	TLSNamespace = "urn:ietf:params:xml:ns:xmpp-tls"
+
+	// SASLNamespace is the SASL feature namespace.
+	SASLNamespace = "urn:ietf:params:xml:ns:xmpp-sasl"
+
+	// BindNamespace is the resource binding feature namespace.
+	BindNamespace = "urn:ietf:params:xml:ns:xmpp-bind"
+
+	// SessionNamespace is the session establishment feature namespace.
+	SessionNamespace = "urn:ietf:params:xml:ns:xmpp-session"
+
+	// StreamManagementNamespace is the stream management (XEP-0198) feature namespace.
+	StreamManagementNamespace = "urn:xmpp:sm:3"
+
+	// CSINamespace is the client state indication (XEP-0352) feature namespace.
+	CSINamespace = "urn:xmpp:csi:0"
+
+	// CompressionNamespace is the stream compression (XEP-0138) feature namespace.
+	CompressionNamespace = "http://jabber.org/features/compress"
+)
+
+const featuresName = streamPrefix + ":features"
+
+// StartTLS represents a STARTTLS feature advertisement.
+type StartTLS struct {
+	// Required tells whether TLS negotiation is mandatory-to-negotiate.
+	Required bool
+}
+
+// Session represents a session establishment feature advertisement (RFC 3921).
+type Session struct {
+	// Optional tells whether session establishment is voluntary-to-negotiate.
+	Optional bool
+}
+
+// Features represents a <stream:features/> element.
+type Features struct {
+	// StartTLS is the STARTTLS feature. It's nil if not advertised.
+	StartTLS *StartTLS
+
+	// Mechanisms contains the offered SASL mechanisms. SASL is not advertised if empty.
+	Mechanisms []string
+
+	// Bind tells whether resource binding is advertised.
+	Bind bool
+
+	// Session is the session establishment feature. It's nil if not advertised.
+	Session *Session
+
+	// StreamManagement tells whether stream management is advertised.
+	StreamManagement bool
+
+	// ClientStateIndication tells whether client state indication is advertised.
+	ClientStateIndication bool
+
+	// CompressionMethods contains the offered stream compression methods. Compression is not advertised if empty.
+	CompressionMethods []string
+
+	// Other contains any other advertised feature element.
+	Other []stravaganza.Element
+}
+
+// HasMandatory tells whether any of the advertised features is mandatory-to-negotiate.
+// As stated in RFC 6120 section 4.3.2, the stream negotiation process is complete
+// once features contain only voluntary-to-negotiate features.
+// Features contained in Other are considered voluntary-to-negotiate.
+func (f *Features) HasMandatory() bool {
+	return (f.StartTLS != nil && f.StartTLS.Required) ||
+		len(f.Mechanisms) > 0 ||
+		f.Bind ||
+		(f.Session != nil && !f.Session.Optional)
+}
+
+// Element returns the <stream:features/> element representation of f.
+func (f *Features) Element() stravaganza.Element {
+	b := stravaganza.NewBuilder(featuresName)
+	if f.StartTLS != nil {
+		tb := stravaganza.NewBuilder("starttls").
+			WithAttribute(stravaganza.Namespace, TLSNamespace)
+		if f.StartTLS.Required {
+			tb.WithChild(stravaganza.NewBuilder("required").Build())
+		}
+		b.WithChild(tb.Build())
+	}
+	if len(f.Mechanisms) > 0 {
+		b.WithChild(listElement("mechanisms", SASLNamespace, "mechanism", f.Mechanisms))
+	}
+	if len(f.CompressionMethods) > 0 {
+		b.WithChild(listElement("compression", CompressionNamespace, "method", f.CompressionMethods))
+	}
+	if f.Bind {
+		b.WithChild(emptyElement("bind", BindNamespace))
+	}
+	if f.Session != nil {
+		sb := stravaganza.NewBuilder("session").
+			WithAttribute(stravaganza.Namespace, SessionNamespace)
+		if f.Session.Optional {
+			sb.WithChild(stravaganza.NewBuilder("optional").Build())
+		}
+		b.WithChild(sb.Build())
+	}
+	if f.StreamManagement {
+		b.WithChild(emptyElement("sm", StreamManagementNamespace))
+	}
+	if f.ClientStateIndication {
+		b.WithChild(emptyElement("csi", CSINamespace))
+	}
+	b.WithChildren(f.Other...)
+	return b.Build()
+}
+
+// ParseFeatures parses stream features from a <stream:features/> element.
+func ParseFeatures(el stravaganza.Element) (*Features, error) {
+	if el == nil || stravaganza.LocalName(el) != "features" || (el.Name() != featuresName && stravaganza.NamespaceURI(el) != Namespace) {
+		return nil, errors.New("xmppstream: not a stream features element")
+	}
+	f := &Features{}
+	for _, child := range el.AllChildren() {
+		switch {
+		case isFeature(child, "starttls", TLSNamespace):
+			f.StartTLS = &StartTLS{Required: child.Child("required") != nil}
+		case isFeature(child, "mechanisms", SASLNamespace):
+			f.Mechanisms = listValues(child, "mechanism")
+		case isFeature(child, "compression", CompressionNamespace):
+			f.CompressionMethods = listValues(child, "method")
+		case isFeature(child, "bind", BindNamespace):
+			f.Bind = true
+		case isFeature(child, "session", SessionNamespace):
+			f.Session = &Session{Optional: child.Child("optional") != nil}
+		case isFeature(child, "sm", StreamManagementNamespace):
+			f.StreamManagement = true
+		case isFeature(child, "csi", CSINamespace):
+			f.ClientStateIndication = true
+		default:
+			f.Other = append(f.Other, child)
+		}
+	}
+	return f, nil
+}
+
+func isFeature(el stravaganza.Element, name, ns string) bool {
+	return stravaganza.LocalName(el) == name && stravaganza.NamespaceURI(el) == ns
+}
+
+func emptyElement(name, ns string) stravaganza.Element {
+	return stravaganza.NewBuilder(name).
+		WithAttribute(stravaganza.Namespace, ns).
+		Build()
+}
+
+func listElement(name, ns, itemName string, values []string) stravaganza.Element {
+	b := stravaganza.NewBuilder(name).
+		WithAttribute(stravaganza.Namespace, ns)
+	for _, v := range values {
+		b.WithChild(stravaganza.NewBuilder(itemName).WithText(v).Build())
+	}
+	return b.Build()
+}
+
+func listValues(el stravaganza.Element, itemName string) []string {
+	var values []string
+	for _, item := range el.Children(itemName) {
+		values = append(values, item.Text())
+	}
+	return values
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppstream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza"
+	xmppparser "github.com/jackal-xmpp/stravaganza/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatures_Element(t *testing.T) {
+	// given
+	f := &Features{
+		StartTLS:   &StartTLS{Required: true},
+		Mechanisms: []string{"SCRAM-SHA-1", "PLAIN"},
+	}
+
+	// when
+	el := f.Element()
+
+	// then
+	require.Equal(t, `<stream:features>`+
+		`<starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'><required/></starttls>`+
+		`<mechanisms xmlns='urn:ietf:params:xml:ns:xmpp-sasl'><mechanism>SCRAM-SHA-1</mechanism><mechanism>PLAIN</mechanism></mechanisms>`+
+		`</stream:features>`, el.String())
+}
+
+func TestFeatures_Parse(t *testing.T) {
+	// given
+	src := `<stream:stream xmlns:stream="http://etherx.jabber.org/streams" version="1.0" xmlns="jabber:client">` +
+		`<stream:features>` +
+		`<bind xmlns="urn:ietf:params:xml:ns:xmpp-bind"/>` +
+		`<session xmlns="urn:ietf:params:xml:ns:xmpp-session"><optional/></session>` +
+		`<sm xmlns="urn:xmpp:sm:3"/>` +
+		`<csi xmlns="urn:xmpp:csi:0"/>` +
+		`<compression xmlns="http://jabber.org/features/compress"><method>zlib</method></compression>` +
+		`<ver xmlns="urn:xmpp:features:rosterver"/>` +
+		`</stream:features>`
+	p := xmppparser.New(strings.NewReader(src), xmppparser.SocketStream, 1024)
+	_, err := p.Parse()
+	require.Nil(t, err)
+	el, err := p.Parse()
+	require.Nil(t, err)
+
+	// when
+	f, err := ParseFeatures(el)
+
+	// then
+	require.Nil(t, err)
+	require.Nil(t, f.StartTLS)
+	require.Nil(t, f.Mechanisms)
+	require.True(t, f.Bind)
+	require.NotNil(t, f.Session)
+	require.True(t, f.Session.Optional)
+	require.True(t, f.StreamManagement)
+	require.True(t, f.ClientStateIndication)
+	require.Equal(t, []string{"zlib"}, f.CompressionMethods)
+	require.Len(t, f.Other, 1)
+	require.Equal(t, "ver", f.Other[0].Name())
+
+	f2, err := ParseFeatures(f.Element())
+	require.Nil(t, err)
+	require.Equal(t, f.Element().String(), f2.Element().String())
+}
+
+func TestFeatures_ParseNamespaced(t *testing.T) {
+	// given
+	el := stravaganza.NewBuilder("features").
+		WithAttribute(stravaganza.Namespace, Namespace).
+		WithChild(emptyElement("bind", BindNamespace)).
+		Build()
+
+	// when
+	f, err := ParseFeatures(el)
+	_, err2 := ParseFeatures(stravaganza.NewBuilder("features").Build())
+
+	// then
+	require.Nil(t, err)
+	require.True(t, f.Bind)
+	require.NotNil(t, err2)
+}
+
+func TestFeatures_HasMandatory(t *testing.T) {
+	var tcs = []struct {
+		name      string
+		features  Features
+		mandatory bool
+	}{
+		{name: "Empty", features: Features{}},
+		{name: "VoluntaryTLS", features: Features{StartTLS: &StartTLS{}}},
+		{name: "RequiredTLS", features: Features{StartTLS: &StartTLS{Required: true}}, mandatory: true},
+		{name: "SASL", features: Features{Mechanisms: []string{"PLAIN"}}, mandatory: true},
+		{name: "Bind", features: Features{Bind: true, StreamManagement: true}, mandatory: true},
+		{name: "OptionalSession", features: Features{Session: &Session{Optional: true}, ClientStateIndication: true}},
+		{name: "RequiredSession", features: Features{Session: &Session{}}, mandatory: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.mandatory, tc.features.HasMandatory())
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppstream
+
+import (
+	"errors"
+	"io"
+
+	"github.com/jackal-xmpp/stravaganza"
+	streamerror "github.com/jackal-xmpp/stravaganza/errors/stream"
+	"github.com/jackal-xmpp/stravaganza/jid"
+)
+
+// FramingNamespace is the WebSocket framing namespace (RFC 7395).
+const FramingNamespace = "urn:ietf:params:xml:ns:xmpp-framing"
+
+// Open represents a WebSocket <open/> framing element, which replaces the stream opening tag.
+type Open struct {
+	// To is the stream 'to' address. It might be nil if absent.
+	To *jid.JID
+
+	// From is the stream 'from' address. It might be nil if absent.
+	From *jid.JID
+
+	// Version is the stream version, in 'major.minor' form.
+	Version string
+
+	// Lang is the stream default language tag ('xml:lang').
+	Lang string
+
+	// ID is the stream identifier, only set by the receiving entity.
+	ID string
+}
+
+// Header returns the stream header equivalent to o.
+// Given that RFC 7395 only defines client-to-server streams, header namespace is always ClientNamespace.
+func (o *Open) Header() *StreamHeader {
+	return &StreamHeader{
+		To:        o.To,
+		From:      o.From,
+		Version:   o.Version,
+		Lang:      o.Lang,
+		ID:        o.ID,
+		Namespace: ClientNamespace,
+	}
+}
+
+// Element returns the <open/> element representation of o.
+func (o *Open) Element() stravaganza.Element {
+	b := stravaganza.NewBuilder("open").
+		WithAttribute(stravaganza.Namespace, FramingNamespace)
+	if o.To != nil {
+		b.WithAttribute(stravaganza.To, o.To.String())
+	}
+	if o.From != nil {
+		b.WithAttribute(stravaganza.From, o.From.String())
+	}
+	if len(o.ID) > 0 {
+		b.WithAttribute(stravaganza.ID, o.ID)
+	}
+	if len(o.Version) > 0 {
+		b.WithAttribute(stravaganza.Version, o.Version)
+	}
+	if len(o.Lang) > 0 {
+		b.WithAttribute(stravaganza.Language, o.Lang)
+	}
+	return b.Build()
+}
+
+// ToXML writes the <open/> framing element to w.
+func (o *Open) ToXML(w io.Writer) error {
+	return o.Element().ToXML(w, true)
+}
+
+// ParseOpen parses an <open/> framing element.
+// Any returned error will be a *streamerror.Error value. As in the case of ParseHeader, the returned
+// value is not validated, which can be done by means of its stream header equivalent.
+func ParseOpen(el stravaganza.Element) (*Open, error) {
+	if el == nil || stravaganza.LocalName(el) != "open" || stravaganza.NamespaceURI(el) != FramingNamespace {
+		return nil, &streamerror.Error{
+			Reason: streamerror.InvalidNamespace,
+			Err:    errors.New("xmppstream: not an open framing element"),
+		}
+	}
+	o := &Open{
+		Version: el.Attribute(stravaganza.Version),
+		Lang:    el.Attribute(stravaganza.Language),
+		ID:      el.Attribute(stravaganza.ID),
+	}
+	if to := el.Attribute(stravaganza.To); len(to) > 0 {
+		j, err := jid.NewWithString(to, false)
+		if err != nil {
+			return nil, &streamerror.Error{Reason: streamerror.HostUnknown, Err: err}
+		}
+		o.To = j
+	}
+	if from := el.Attribute(stravaganza.From); len(from) > 0 {
+		j, err := jid.NewWithString(from, false)
+		if err != nil {
+			return nil, &streamerror.Error{Reason: streamerror.InvalidFrom, Err: err}
+		}
+		o.From = j
+	}
+	return o, nil
+}
+
+// Close represents a WebSocket <close/> framing element, which replaces the stream closing tag.
+type Close struct {
+	// SeeOtherURI is the WebSocket endpoint the client should reconnect to, if any (RFC 7395 section 3.6.1).
+	SeeOtherURI string
+}
+
+// Element returns the <close/> element representation of c.
+func (c *Close) Element() stravaganza.Element {
+	b := stravaganza.NewBuilder("close").
+		WithAttribute(stravaganza.Namespace, FramingNamespace)
+	if len(c.SeeOtherURI) > 0 {
+		b.WithAttribute("see-other-uri", c.SeeOtherURI)
+	}
+	return b.Build()
+}
+
+// ToXML writes the <close/> framing element to w.
+func (c *Close) ToXML(w io.Writer) error {
+	return c.Element().ToXML(w, true)
+}
+
+// ParseClose parses a <close/> framing element.
+func ParseClose(el stravaganza.Element) (*Close, error) {
+	if el == nil || stravaganza.LocalName(el) != "close" || stravaganza.NamespaceURI(el) != FramingNamespace {
+		return nil, errors.New("xmppstream: not a close framing element")
+	}
+	return &Close{SeeOtherURI: el.Attribute("see-other-uri")}, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppstream
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza"
+	streamerror "github.com/jackal-xmpp/stravaganza/errors/stream"
+	"github.com/jackal-xmpp/stravaganza/jid"
+	xmppparser "github.com/jackal-xmpp/stravaganza/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFraming_Open(t *testing.T) {
+	// given
+	from, _ := jid.NewWithString("example.com", false)
+	o := &Open{
+		From:    from,
+		ID:      "++TR84Sm6A3hnt3Q065SnAbbk3Y=",
+		Version: DefaultVersion,
+		Lang:    "en",
+	}
+
+	// when
+	buf := bytes.NewBuffer(nil)
+	err := o.ToXML(buf)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, `<open xmlns='urn:ietf:params:xml:ns:xmpp-framing' from='example.com'`+
+		` id='++TR84Sm6A3hnt3Q065SnAbbk3Y=' version='1.0' xml:lang='en'/>`, buf.String())
+
+	el, err := xmppparser.ParseFrame(buf.Bytes(), 1024)
+	require.Nil(t, err)
+
+	o2, err := ParseOpen(el)
+	require.Nil(t, err)
+	require.Equal(t, o, o2)
+	require.Nil(t, o2.Header().Validate())
+	require.Equal(t, ClientNamespace, o2.Header().Namespace)
+}
+
+func TestFraming_ParseOpenErrors(t *testing.T) {
+	_, err1 := ParseOpen(stravaganza.NewBuilder("open").Build())
+	_, err2 := ParseOpen(
+		stravaganza.NewBuilder("open").
+			WithAttribute(stravaganza.Namespace, FramingNamespace).
+			WithAttribute(stravaganza.From, "@example.com").
+			Build(),
+	)
+
+	var se *streamerror.Error
+	require.True(t, errors.As(err1, &se))
+	require.Equal(t, streamerror.InvalidNamespace, se.Reason)
+	require.True(t, errors.As(err2, &se))
+	require.Equal(t, streamerror.InvalidFrom, se.Reason)
+}
+
+func TestFraming_Close(t *testing.T) {
+	// given
+	c := &Close{SeeOtherURI: "wss://otherendpoint.example/xmpp-bind"}
+
+	// when
+	buf := bytes.NewBuffer(nil)
+	err := c.ToXML(buf)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, `<close xmlns='urn:ietf:params:xml:ns:xmpp-framing' see-other-uri='wss://otherendpoint.example/xmpp-bind'/>`, buf.String())
+
+	el, err := xmppparser.ParseFrame(buf.Bytes(), 1024)
+	require.Equal(t, xmppparser.ErrStreamClosedByPeer, err)
+
+	c2, err := ParseClose(el)
+	require.Nil(t, err)
+	require.Equal(t, c, c2)
+
+	_, err = ParseClose(stravaganza.NewBuilder("close").Build())
+	require.NotNil(t, err)
+}
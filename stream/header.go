@@ -0,0 +1,204 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package xmppstream provides typed representations of the XMPP stream level elements:
+// the stream opening header and the stream features advertisement, as defined in RFC 6120,
+// along with their WebSocket framing counterparts, as defined in RFC 7395.
+package xmppstream
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jackal-xmpp/stravaganza"
+	streamerror "github.com/jackal-xmpp/stravaganza/errors/stream"
+	"github.com/jackal-xmpp/stravaganza/jid"
+)
+
+const (
+	// Namespace is the stream namespace, bound to the 'stream' prefix.
+	Namespace = "http://etherx.jabber.org/streams"
+
+	// ClientNamespace is the content namespace of client-to-server streams.
+	ClientNamespace = "jabber:client"
+
+	// ServerNamespace is the content namespace of server-to-server streams.
+	ServerNamespace = "jabber:server"
+
+	// DefaultVersion is the stream version defined by RFC 6120.
+	DefaultVersion = "1.0"
+)
+
+const (
+	streamPrefix = "stream"
+	streamName   = streamPrefix + ":stream"
+)
+
+// StreamHeader represents the attributes of a <stream:stream> opening tag.
+type StreamHeader struct {
+	// To is the stream 'to' address. It might be nil if absent.
+	To *jid.JID
+
+	// From is the stream 'from' address. It might be nil if absent.
+	From *jid.JID
+
+	// Version is the stream version, in 'major.minor' form.
+	Version string
+
+	// Lang is the stream default language tag ('xml:lang').
+	Lang string
+
+	// ID is the stream identifier, only set by the receiving entity.
+	ID string
+
+	// Namespace is the stream content namespace, either ClientNamespace or ServerNamespace.
+	Namespace string
+}
+
+// Validate checks the header is acceptable according to RFC 6120 section 4.
+// Any returned error will be a *streamerror.Error value, ready to be sent to the peer.
+func (h *StreamHeader) Validate() error {
+	if h.Namespace != ClientNamespace && h.Namespace != ServerNamespace {
+		return &streamerror.Error{
+			Reason: streamerror.InvalidNamespace,
+			Err:    fmt.Errorf("xmppstream: invalid content namespace: %q", h.Namespace),
+		}
+	}
+	major, _, err := parseVersion(h.Version)
+	if err != nil {
+		return &streamerror.Error{Reason: streamerror.UnsupportedVersion, Err: err}
+	}
+	if major != 1 {
+		return &streamerror.Error{
+			Reason: streamerror.UnsupportedVersion,
+			Err:    fmt.Errorf("xmppstream: unsupported version: %s", h.Version),
+		}
+	}
+	return nil
+}
+
+// Element returns the <stream:stream> element representation of the header.
+// The returned element is empty, so its serialization must not include the closing tag.
+func (h *StreamHeader) Element() stravaganza.Element {
+	b := stravaganza.NewBuilder(streamName)
+	if len(h.Namespace) > 0 {
+		b.WithAttribute(stravaganza.Namespace, h.Namespace)
+	}
+	b.WithAttribute(stravaganza.StreamNamespace, Namespace)
+	if h.To != nil {
+		b.WithAttribute(stravaganza.To, h.To.String())
+	}
+	if h.From != nil {
+		b.WithAttribute(stravaganza.From, h.From.String())
+	}
+	if len(h.ID) > 0 {
+		b.WithAttribute(stravaganza.ID, h.ID)
+	}
+	if len(h.Version) > 0 {
+		b.WithAttribute(stravaganza.Version, h.Version)
+	}
+	if len(h.Lang) > 0 {
+		b.WithAttribute(stravaganza.Language, h.Lang)
+	}
+	return b.Build()
+}
+
+// ToXML writes the stream opening tag to w.
+func (h *StreamHeader) ToXML(w io.Writer) error {
+	return h.Element().ToXML(w, false)
+}
+
+// String returns the stream opening tag.
+func (h *StreamHeader) String() string {
+	var sb strings.Builder
+	_ = h.ToXML(&sb)
+	return sb.String()
+}
+
+// ParseHeader parses a stream header from a <stream:stream> element, as returned by the parser
+// in SocketStream mode.
+// Any returned error will be a *streamerror.Error value. Note that the returned header is not
+// validated, in order to let the receiving entity decide how to handle it by means of Validate.
+func ParseHeader(el stravaganza.Element) (*StreamHeader, error) {
+	if el == nil {
+		return nil, errors.New("xmppstream: nil stream element")
+	}
+	if el.Name() != streamName {
+		if stravaganza.LocalName(el) == streamPrefix && stravaganza.NamespaceURI(el) == Namespace {
+			return nil, &streamerror.Error{
+				Reason: streamerror.BadNamespacePrefix,
+				Err:    fmt.Errorf("xmppstream: unsupported stream prefix: %s", el.Name()),
+			}
+		}
+		return nil, &streamerror.Error{
+			Reason: streamerror.InvalidNamespace,
+			Err:    fmt.Errorf("xmppstream: unexpected stream element: %s", el.Name()),
+		}
+	}
+	if ns := stravaganza.NamespaceURI(el); ns != Namespace {
+		return nil, &streamerror.Error{
+			Reason: streamerror.InvalidNamespace,
+			Err:    fmt.Errorf("xmppstream: invalid stream namespace: %q", ns),
+		}
+	}
+	h := &StreamHeader{
+		Version:   el.Attribute(stravaganza.Version),
+		Lang:      el.Attribute(stravaganza.Language),
+		ID:        el.Attribute(stravaganza.ID),
+		Namespace: el.Attribute(stravaganza.Namespace),
+	}
+	if to := el.Attribute(stravaganza.To); len(to) > 0 {
+		j, err := jid.NewWithString(to, false)
+		if err != nil {
+			return nil, &streamerror.Error{Reason: streamerror.HostUnknown, Err: err}
+		}
+		h.To = j
+	}
+	if from := el.Attribute(stravaganza.From); len(from) > 0 {
+		j, err := jid.NewWithString(from, false)
+		if err != nil {
+			return nil, &streamerror.Error{Reason: streamerror.InvalidFrom, Err: err}
+		}
+		h.From = j
+	}
+	return h, nil
+}
+
+// parseVersion parses a 'major.minor' stream version.
+// As stated in RFC 6120 section 4.7.5, leading zeros are ignored and each part is treated as a separate integer.
+func parseVersion(v string) (major, minor int, err error) {
+	if len(v) == 0 {
+		return 0, 0, errors.New("xmppstream: missing stream version")
+	}
+	majorStr, minorStr, ok := strings.Cut(v, ".")
+	if !ok {
+		return 0, 0, fmt.Errorf("xmppstream: malformed stream version: %s", v)
+	}
+	major, err1 := parseVersionPart(majorStr)
+	minor, err2 := parseVersionPart(minorStr)
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("xmppstream: malformed stream version: %s", v)
+	}
+	return major, minor, nil
+}
+
+func parseVersionPart(s string) (int, error) {
+	if len(s) == 0 || strings.TrimLeft(s, "0123456789") != "" {
+		return 0, errors.New("not a number")
+	}
+	return strconv.Atoi(s)
+}
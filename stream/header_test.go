@@ -0,0 +1,153 @@
+// Copyright 2022 The jackal Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmppstream
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackal-xmpp/stravaganza"
+	streamerror "github.com/jackal-xmpp/stravaganza/errors/stream"
+	"github.com/jackal-xmpp/stravaganza/jid"
+	xmppparser "github.com/jackal-xmpp/stravaganza/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamHeader_ToXML(t *testing.T) {
+	// given
+	to, _ := jid.NewWithString("im.example.com", false)
+	from, _ := jid.NewWithString("juliet@im.example.com", false)
+	h := &StreamHeader{
+		To:        to,
+		From:      from,
+		Version:   DefaultVersion,
+		Lang:      "en",
+		ID:        "++TR84Sm6A3hnt3Q065SnAbbk3Y=",
+		Namespace: ClientNamespace,
+	}
+
+	// when
+	str := h.String()
+
+	// then
+	require.Equal(t, `<stream:stream xmlns='jabber:client' xmlns:stream='http://etherx.jabber.org/streams'`+
+		` to='im.example.com' from='juliet@im.example.com' id='++TR84Sm6A3hnt3Q065SnAbbk3Y=' version='1.0' xml:lang='en'>`, str)
+}
+
+func TestStreamHeader_Parse(t *testing.T) {
+	// given
+	src := `<stream:stream xmlns:stream="http://etherx.jabber.org/streams" version="1.0" xmlns="jabber:client"` +
+		` from="juliet@im.example.com" to="im.example.com" xml:lang="en">`
+	p := xmppparser.New(strings.NewReader(src), xmppparser.SocketStream, 1024)
+	el, err := p.Parse()
+	require.Nil(t, err)
+
+	// when
+	h, err := ParseHeader(el)
+
+	// then
+	require.Nil(t, err)
+	require.Nil(t, h.Validate())
+	require.Equal(t, "im.example.com", h.To.String())
+	require.Equal(t, "juliet@im.example.com", h.From.String())
+	require.Equal(t, "1.0", h.Version)
+	require.Equal(t, "en", h.Lang)
+	require.Equal(t, ClientNamespace, h.Namespace)
+	require.Equal(t, "", h.ID)
+
+	h2, err := ParseHeader(h.Element())
+	require.Nil(t, err)
+	require.Equal(t, h, h2)
+}
+
+func TestStreamHeader_ParseErrors(t *testing.T) {
+	var tcs = []struct {
+		name   string
+		el     stravaganza.Element
+		reason streamerror.Reason
+	}{
+		{
+			name: "InvalidNamespace",
+			el: stravaganza.NewBuilder("stream:stream").
+				WithAttribute(stravaganza.StreamNamespace, "urn:other").
+				Build(),
+			reason: streamerror.InvalidNamespace,
+		},
+		{
+			name: "BadNamespacePrefix",
+			el: stravaganza.NewBuilder("s:stream").
+				WithAttribute("xmlns:s", Namespace).
+				Build(),
+			reason: streamerror.BadNamespacePrefix,
+		},
+		{
+			name: "InvalidTo",
+			el: stravaganza.NewBuilder("stream:stream").
+				WithAttribute(stravaganza.StreamNamespace, Namespace).
+				WithAttribute(stravaganza.To, "@im.example.com").
+				Build(),
+			reason: streamerror.HostUnknown,
+		},
+		{
+			name: "InvalidFrom",
+			el: stravaganza.NewBuilder("stream:stream").
+				WithAttribute(stravaganza.StreamNamespace, Namespace).
+				WithAttribute(stravaganza.From, "juliet@").
+				Build(),
+			reason: streamerror.InvalidFrom,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseHeader(tc.el)
+
+			var se *streamerror.Error
+			require.True(t, errors.As(err, &se))
+			require.Equal(t, tc.reason, se.Reason)
+		})
+	}
+}
+
+func TestStreamHeader_Validate(t *testing.T) {
+	var tcs = []struct {
+		name    string
+		header  StreamHeader
+		invalid bool
+		reason  streamerror.Reason
+	}{
+		{name: "Client", header: StreamHeader{Namespace: ClientNamespace, Version: "1.0"}},
+		{name: "Server", header: StreamHeader{Namespace: ServerNamespace, Version: "1.0"}},
+		{name: "MinorVersion", header: StreamHeader{Namespace: ClientNamespace, Version: "1.12"}},
+		{name: "LeadingZeros", header: StreamHeader{Namespace: ClientNamespace, Version: "01.00"}},
+		{name: "InvalidNamespace", header: StreamHeader{Namespace: "jabber:other", Version: "1.0"}, invalid: true, reason: streamerror.InvalidNamespace},
+		{name: "MissingVersion", header: StreamHeader{Namespace: ClientNamespace}, invalid: true, reason: streamerror.UnsupportedVersion},
+		{name: "MajorVersion", header: StreamHeader{Namespace: ClientNamespace, Version: "2.0"}, invalid: true, reason: streamerror.UnsupportedVersion},
+		{name: "MalformedVersion", header: StreamHeader{Namespace: ClientNamespace, Version: "1.a"}, invalid: true, reason: streamerror.UnsupportedVersion},
+		{name: "SignedVersion", header: StreamHeader{Namespace: ClientNamespace, Version: "+1.0"}, invalid: true, reason: streamerror.UnsupportedVersion},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.header.Validate()
+			if !tc.invalid {
+				require.Nil(t, err)
+				return
+			}
+			var se *streamerror.Error
+			require.True(t, errors.As(err, &se))
+			require.Equal(t, tc.reason, se.Reason)
+		})
+	}
+}